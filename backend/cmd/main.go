@@ -2,24 +2,48 @@ package main
 
 import (
 	"backend/configs"
-	"backend/internal/models"
+	adminCtrl "backend/internal/controllers/admin"
+	customerCtrl "backend/internal/controllers/customer"
+	adminRepo "backend/internal/repository/admin"
+	customerRepo "backend/internal/repository/customer"
 	"backend/internal/routes"
+	"backend/migrations"
 	"log"
 	"net/http"
 	"os"
 	"time"
 
-	"github.com/rs/cors"
 	"github.com/gorilla/mux"
+	"github.com/rs/cors"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(os.Args[2:])
+		return
+	}
+
 	// Kết nối database
 	configs.ConnectDatabase()
 
-	// Auto migrate
-	if err := configs.DB.AutoMigrate(&models.User{}); err != nil {
-		log.Fatal("Migration failed:", err)
+	// Schema được quản lý bằng migration (xem thư mục migrations, chạy "backend migrate up")
+	pending, err := migrations.Pending(configs.DB)
+	if err != nil {
+		log.Fatal("Failed to check migrations:", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("%d pending migration(s), run \"backend migrate up\" first", len(pending))
+	}
+
+	// Repository admin dùng chung 1 *gorm.DB
+	db := configs.DB
+	userRepo := adminRepo.NewUserRepository(db)
+	roleRepo := adminRepo.NewRoleRepository(db)
+	orderRepo := adminRepo.NewOrderRepository(db)
+
+	// Seed permission + role hệ thống
+	if err := roleRepo.SeedRolesAndPermissions(); err != nil {
+		log.Fatal("Seeding roles failed:", err)
 	}
 
 	r := mux.NewRouter()
@@ -27,14 +51,31 @@ func main() {
 	// Setup routes
 	routes.SetupRoutes(r)
 	// Nếu bạn có admin routes riêng
-	routes.SetupAdminRoutes(r)
+	routes.SetupAdminRoutes(r, routes.AdminControllers{
+		Users:      adminCtrl.NewUserController(userRepo),
+		Roles:      adminCtrl.NewRoleController(roleRepo),
+		Suppliers:  adminCtrl.NewSupplierController(adminRepo.NewSupplierRepository(db)),
+		Purchases:  adminCtrl.NewPurchaseController(adminRepo.NewPurchaseRepository(db)),
+		Categories: adminCtrl.NewCategoryController(adminRepo.NewCategoryRepository(db)),
+		Products:   adminCtrl.NewProductController(adminRepo.NewProductRepository(db)),
+		Inventory:  adminCtrl.NewInventoryController(adminRepo.NewInventoryRepository(db)),
+		Orders:     adminCtrl.NewOrderController(orderRepo),
+		Search:     adminCtrl.NewSearchController(adminRepo.NewSearchRepository(db)),
+	})
+	// Storefront public
+	routes.SetupShopRoutes(r)
+	// API của customer đã đăng nhập
+	// Customer hủy đơn dùng chung logic chuyển status của order repository admin
+	routes.SetupCustomerRoutes(r, routes.CustomerControllers{
+		Orders: customerCtrl.NewOrderController(customerRepo.NewOrderRepository(db, orderRepo)),
+	})
 
 	// CORS middleware
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{"http://localhost:5173"}, // FE React
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Origin", "Content-Type", "Authorization"},
-		ExposedHeaders:   []string{"Content-Length"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Origin", "Content-Type", "Authorization", "X-Cart-Token", "If-Match"},
+		ExposedHeaders:   []string{"Content-Length", "X-Cart-Token", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           int((12 * time.Hour).Seconds()),
 	})
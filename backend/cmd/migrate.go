@@ -0,0 +1,86 @@
+package main
+
+import (
+	"backend/configs"
+	"backend/migrations"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `Usage: backend migrate <command>
+
+Commands:
+  up [n]              chạy n migration chưa chạy (mặc định: tất cả)
+  down [n]            rollback n migration mới nhất (mặc định: 1)
+  status              liệt kê migration và trạng thái
+  baseline <version>  đánh dấu đã chạy tới <version> mà không thực thi SQL (DB cũ)`
+
+// runMigrate xử lý subcommand "migrate"
+func runMigrate(args []string) {
+	if len(args) == 0 {
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+
+	n := 0
+	if len(args) > 1 {
+		v, err := strconv.Atoi(args[1])
+		if err != nil || v < 0 {
+			log.Fatalf("Invalid number: %s", args[1])
+		}
+		n = v
+	}
+
+	configs.ConnectDatabase()
+	db := configs.DB
+
+	switch args[0] {
+	case "up":
+		done, err := migrations.Up(db, n)
+		printMigrations("Applied", done)
+		if err != nil {
+			log.Fatal("Migration failed: ", err)
+		}
+		if len(done) == 0 {
+			log.Println("No pending migrations")
+		}
+	case "down":
+		done, err := migrations.Down(db, n)
+		printMigrations("Rolled back", done)
+		if err != nil {
+			log.Fatal("Rollback failed: ", err)
+		}
+	case "status":
+		statuses, err := migrations.GetStatus(db)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-40s %s\n", s.Version, s.Name, applied)
+		}
+	case "baseline":
+		if len(args) < 2 {
+			log.Fatal("baseline requires a version")
+		}
+		done, err := migrations.Baseline(db, int64(n))
+		printMigrations("Marked as applied", done)
+		if err != nil {
+			log.Fatal(err)
+		}
+	default:
+		fmt.Println(migrateUsage)
+		os.Exit(2)
+	}
+}
+
+func printMigrations(action string, list []migrations.Migration) {
+	for _, m := range list {
+		log.Printf("%s %04d_%s", action, m.Version, m.Name)
+	}
+}
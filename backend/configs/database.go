@@ -1,12 +1,15 @@
 package configs
 
 import (
+	"backend/internal/dberr"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/glebarez/sqlite"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
@@ -15,24 +18,67 @@ var DB *gorm.DB
 func ConnectDatabase() {
 	_ = godotenv.Load()
 
+	dialector, err := Dialector(os.Getenv("DB_DRIVER"))
+	if err != nil {
+		log.Fatal("❌ Failed to connect database:", err)
+	}
+
+	db, err := Open(dialector)
+	if err != nil {
+		log.Fatal("❌ Failed to connect database:", err)
+	}
+
+	DB = db
+	log.Println("✅ Database connected successfully (" + db.Dialector.Name() + ")")
+}
+
+// Open mở kết nối gorm và gắn callback chuyển lỗi ràng buộc (unique / foreign key) sang lỗi có kiểu
+func Open(dialector gorm.Dialector) (*gorm.DB, error) {
+	db, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if err := dberr.Register(db); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Dialector tạo gorm dialector theo DB_DRIVER: mysql (mặc định), postgres, sqlite
+func Dialector(driver string) (gorm.Dialector, error) {
 	user := os.Getenv("DB_USER")
 	password := os.Getenv("DB_PASSWORD")
 	host := os.Getenv("DB_HOST")
 	port := os.Getenv("DB_PORT")
 	name := os.Getenv("DB_NAME")
 
-	if port == "" {
-		port = "3306"
-	}
+	switch driver {
+	case "", "mysql":
+		if port == "" {
+			port = "3306"
+		}
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			user, password, host, port, name)
+		return mysql.Open(dsn), nil
 
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		user, password, host, port, name)
+	case "postgres":
+		if port == "" {
+			port = "5432"
+		}
+		sslMode := os.Getenv("DB_SSLMODE")
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+			host, user, password, name, port, sslMode)
+		return postgres.Open(dsn), nil
 
-	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
-	if err != nil {
-		log.Fatal("❌ Failed to connect database:", err)
+	case "sqlite":
+		// DB_NAME là đường dẫn file, ":memory:" cho DB tạm (dev/test)
+		if name == "" {
+			name = "clothing.db"
+		}
+		return sqlite.Open(name + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), nil
 	}
-
-	DB = db
-	log.Println("✅ Database connected successfully")
+	return nil, fmt.Errorf("unsupported DB_DRIVER %q (mysql, postgres, sqlite)", driver)
 }
@@ -0,0 +1,133 @@
+// Package apperr định nghĩa lỗi nghiệp vụ có kiểu (NotFound, Conflict, Validation, Forbidden, ...).
+// Repository / service trả các lỗi này, package response map Kind sang HTTP status và mã lỗi.
+package apperr
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// Kind là loại lỗi, quyết định HTTP status
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindBadRequest
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindPreconditionFailed
+	KindPreconditionRequired
+	KindLocked
+	KindTooManyRequests
+)
+
+// defaultCodes: mã lỗi mặc định của từng Kind (FE dựa vào code, không dựa vào message)
+var defaultCodes = map[Kind]string{
+	KindInternal:             "internal_error",
+	KindBadRequest:           "bad_request",
+	KindValidation:           "validation_failed",
+	KindUnauthorized:         "unauthorized",
+	KindForbidden:            "forbidden",
+	KindNotFound:             "not_found",
+	KindConflict:             "conflict",
+	KindPreconditionFailed:   "precondition_failed",
+	KindPreconditionRequired: "precondition_required",
+	KindLocked:               "locked",
+	KindTooManyRequests:      "too_many_requests",
+}
+
+// Error là lỗi nghiệp vụ có kiểu. Message được trả cho client, Err (nguyên nhân) thì không.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{}
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New tạo lỗi với mã mặc định của kind
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Code: defaultCodes[kind], Message: message}
+}
+
+// WithCode trả bản sao với mã lỗi riêng (vd "version_conflict"); không sửa lỗi gốc
+// nên dùng được trên các biến lỗi dùng chung (ErrXxx)
+func (e *Error) WithCode(code string) *Error {
+	c := *e
+	c.Code = code
+	return &c
+}
+
+// WithDetails trả bản sao kèm chi tiết (lỗi theo field, danh sách bản ghi phụ thuộc, ...)
+func (e *Error) WithDetails(details interface{}) *Error {
+	c := *e
+	c.Details = details
+	return &c
+}
+
+// Wrap trả bản sao giữ nguyên kind/code/message, gắn thêm nguyên nhân
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// Is: các bản sao tạo từ WithCode / WithDetails / Wrap vẫn khớp lỗi gốc khi cùng kind và code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code && t.Message == e.Message
+}
+
+func BadRequest(message string) *Error { return New(KindBadRequest, message) }
+
+func Validation(message string) *Error { return New(KindValidation, message) }
+
+func Unauthorized(message string) *Error { return New(KindUnauthorized, message) }
+
+func Forbidden(message string) *Error { return New(KindForbidden, message) }
+
+func NotFound(message string) *Error { return New(KindNotFound, message) }
+
+func Conflict(message string) *Error { return New(KindConflict, message) }
+
+func PreconditionFailed(message string) *Error { return New(KindPreconditionFailed, message) }
+
+func PreconditionRequired(message string) *Error { return New(KindPreconditionRequired, message) }
+
+func Locked(message string) *Error { return New(KindLocked, message) }
+
+func TooManyRequests(message string) *Error { return New(KindTooManyRequests, message) }
+
+// Internal bọc lỗi hệ thống; chỉ message được trả cho client
+func Internal(message string, err error) *Error {
+	return New(KindInternal, message).Wrap(err)
+}
+
+// FromRepo chuẩn hóa lỗi trả về từ repository: lỗi đã có kiểu giữ nguyên,
+// gorm.ErrRecordNotFound -> NotFound(notFoundMsg), còn lại -> Internal(failMsg)
+func FromRepo(err error, notFoundMsg, failMsg string) error {
+	var appErr *Error
+	switch {
+	case errors.As(err, &appErr):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return NotFound(notFoundMsg).Wrap(err)
+	default:
+		return Internal(failMsg, err)
+	}
+}
@@ -1,120 +1,170 @@
 package admin
 
 import (
+	"backend/internal/apperr"
 	"backend/internal/models"
 	admin "backend/internal/repository/admin"
-	"encoding/json"
+	"backend/internal/response"
+	"backend/internal/utils"
+	"backend/internal/validate"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
 
+// CategoryController xử lý các API admin về category
+type CategoryController struct {
+	categories admin.CategoryRepository
+}
+
+func NewCategoryController(categories admin.CategoryRepository) *CategoryController {
+	return &CategoryController{categories: categories}
+}
+
+// categoryRequest: body tạo / sửa category (group_name bỏ trống = mặc định / giữ nguyên)
+type categoryRequest struct {
+	Name      string `json:"name" validate:"required,max=191"`
+	GroupName string `json:"group_name" validate:"omitempty,enum=category_group"`
+}
+
+func (req categoryRequest) model() *models.Category {
+	return &models.Category{
+		Name:      strings.TrimSpace(req.Name),
+		GroupName: req.GroupName,
+	}
+}
+
 // GET /api/admin/categories
-func GetAllCategories(w http.ResponseWriter, r *http.Request) {
-	cats, err := admin.GetAllCategories()
+func (c *CategoryController) GetAllCategories(w http.ResponseWriter, r *http.Request) {
+	cats, err := c.categories.GetAllCategories()
 	if err != nil {
-		http.Error(w, "Failed to fetch categories", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to fetch categories", err))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"data": cats})
+	response.OK(w, cats)
 }
 
 // GET /api/admin/categories/{id}
-func GetCategoryDetail(w http.ResponseWriter, r *http.Request) {
+func (c *CategoryController) GetCategoryDetail(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid category ID"))
 		return
 	}
 
-	cat, err := admin.GetCategoryDetail(uint(id))
+	cat, err := c.categories.GetCategoryDetail(uint(id))
 	if err != nil {
-		http.Error(w, "Category not found", http.StatusNotFound)
+		response.Error(w, apperr.FromRepo(err, "Category not found", "Failed to fetch category"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(cat)
+	utils.SetETag(w, cat.Version)
+	response.OK(w, cat)
 }
 
 // POST /api/admin/categories
-func CreateCategory(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		Name      string `json:"name"`
-		GroupName string `json:"group_name"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (c *CategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	category := models.Category{
-		Name:      req.Name,
-		GroupName: req.GroupName,
-	}
-	created, err := admin.CreateCategory(&category)
+	created, err := c.categories.CreateCategory(req.model())
 	if err != nil {
-		http.Error(w, "Failed to create category", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to create category", err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(created)
+	response.OK(w, created)
 }
 
-
-// PUT /api/admin/categories/{id}
-func EditCategory(w http.ResponseWriter, r *http.Request) {
+// PUT /api/admin/categories/{id} (bắt buộc If-Match = ETag lấy từ GET)
+func (c *CategoryController) EditCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid category ID"))
 		return
 	}
-
-	var req struct {
-		Name      string `json:"name"`
-		GroupName string `json:"group_name"`
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+
+	var req categoryRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	updated, err := admin.UpdateCategory(uint(id), &models.Category{
-		Name:      req.Name,
-		GroupName: req.GroupName,
-	})
+	updated, err := c.categories.UpdateCategory(uint(id), req.model(), version)
 	if err != nil {
-		http.Error(w, "Failed to update category", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Category not found", "Failed to update category"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(updated)
+	utils.SetETag(w, updated.Version)
+	response.OK(w, updated)
 }
 
-
 // DELETE /api/admin/categories/{id}
-func DeleteCategory(w http.ResponseWriter, r *http.Request) {
+func (c *CategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	idStr := vars["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid category ID"))
 		return
 	}
 
-	if err := admin.DeleteCategory(uint(id)); err != nil {
-		http.Error(w, "Failed to delete category", http.StatusInternalServerError)
+	if err := c.categories.DeleteCategory(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Category not found", "Failed to delete category"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Category deleted successfully"})
+	response.Message(w, "Category deleted successfully")
+}
+
+// GET /api/admin/categories/trash
+func (c *CategoryController) GetTrashedCategories(w http.ResponseWriter, r *http.Request) {
+	items, err := c.categories.GetTrashedCategories()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch trashed categories", err))
+		return
+	}
+	response.OK(w, items)
+}
+
+// POST /api/admin/categories/{id}/restore
+func (c *CategoryController) RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid category ID"))
+		return
+	}
+	if err := c.categories.RestoreCategory(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Category not found in trash", "Failed to restore category"))
+		return
+	}
+	response.Message(w, "Category restored successfully")
+}
+
+// DELETE /api/admin/categories/{id}/purge (xóa vĩnh viễn, chỉ bản ghi đã ở thùng rác)
+func (c *CategoryController) PurgeCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid category ID"))
+		return
+	}
+	if err := c.categories.PurgeCategory(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Category not found in trash", "Failed to purge category"))
+		return
+	}
+	response.Message(w, "Category permanently deleted")
 }
@@ -1,76 +1,121 @@
 package admin
 
 import (
+	"backend/internal/apperr"
 	"backend/internal/models"
 	admin "backend/internal/repository/admin"
-	"encoding/json"
+	"backend/internal/response"
+	"backend/internal/validate"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 )
 
+// InventoryController xử lý các API admin về inventory log
+type InventoryController struct {
+	inventory admin.InventoryRepository
+}
+
+func NewInventoryController(inventory admin.InventoryRepository) *InventoryController {
+	return &InventoryController{inventory: inventory}
+}
+
+// inventoryLogRequest: body tạo log thủ công (adjust: quantity là tồn kho mới)
+type inventoryLogRequest struct {
+	VariantID  uint   `json:"variant_id" validate:"required"`
+	ChangeType string `json:"change_type" validate:"required,enum=inventory_change"`
+	Quantity   int    `json:"quantity" validate:"min=0"`
+	Note       string `json:"note"`
+}
+
+// inventoryNoteRequest: sửa log chỉ đổi được note
+type inventoryNoteRequest struct {
+	Note string `json:"note"`
+}
+
 // GET ALL LOGS
-func GetAllInventoryLogs(w http.ResponseWriter, r *http.Request) {
-	logs, err := admin.GetAllInventoryLogs()
+func (c *InventoryController) GetAllInventoryLogs(w http.ResponseWriter, r *http.Request) {
+	params, ok := listParams(w, r)
+	if !ok {
+		return
+	}
+	logs, meta, err := c.inventory.GetAllInventoryLogs(params)
 	if err != nil {
-		http.Error(w, "Failed to fetch inventory logs", http.StatusInternalServerError)
+		response.Error(w, listError(err, "Failed to fetch inventory logs"))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]interface{}{"data": logs})
+	response.List(w, logs, meta)
 }
 
 // GET LOG DETAIL
-func GetInventoryLogDetail(w http.ResponseWriter, r *http.Request) {
+func (c *InventoryController) GetInventoryLogDetail(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
-	id, _ := strconv.Atoi(idParam)
-	log, err := admin.GetInventoryLogDetail(uint(id))
+	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Inventory log not found", http.StatusNotFound)
+		response.Error(w, apperr.BadRequest("Invalid inventory log ID"))
 		return
 	}
-	json.NewEncoder(w).Encode(log)
+	log, err := c.inventory.GetInventoryLogDetail(uint(id))
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Inventory log not found", "Failed to fetch inventory log"))
+		return
+	}
+	response.OK(w, log)
 }
 
 // CREATE LOG
-func CreateInventoryLog(w http.ResponseWriter, r *http.Request) {
-	var req models.InventoryLog
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (c *InventoryController) CreateInventoryLog(w http.ResponseWriter, r *http.Request) {
+	var req inventoryLogRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
-	log, err := admin.CreateInventoryLog(&req)
+	log, err := c.inventory.CreateInventoryLog(&models.InventoryLog{
+		VariantID:  req.VariantID,
+		ChangeType: req.ChangeType,
+		Quantity:   req.Quantity,
+		Note:       req.Note,
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, apperr.FromRepo(err, "Variant not found", "Failed to create inventory log"))
 		return
 	}
-	json.NewEncoder(w).Encode(log)
+	response.OK(w, log)
 }
 
 // UPDATE LOG (chỉ note)
-func EditInventoryLog(w http.ResponseWriter, r *http.Request) {
+func (c *InventoryController) EditInventoryLog(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
-	id, _ := strconv.Atoi(idParam)
-	var req models.InventoryLog
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid inventory log ID"))
 		return
 	}
-	log, err := admin.UpdateInventoryLog(uint(id), &req)
+	var req inventoryNoteRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+	log, err := c.inventory.UpdateInventoryLog(uint(id), &models.InventoryLog{Note: req.Note})
 	if err != nil {
-		http.Error(w, "Failed to update inventory log", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Inventory log not found", "Failed to update inventory log"))
 		return
 	}
-	json.NewEncoder(w).Encode(log)
+	response.OK(w, log)
 }
 
 // DELETE LOG
-func DeleteInventoryLog(w http.ResponseWriter, r *http.Request) {
+func (c *InventoryController) DeleteInventoryLog(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
-	id, _ := strconv.Atoi(idParam)
-	if err := admin.DeleteInventoryLog(uint(id)); err != nil {
-		http.Error(w, "Failed to delete inventory log", http.StatusInternalServerError)
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid inventory log ID"))
+		return
+	}
+	if err := c.inventory.DeleteInventoryLog(uint(id)); err != nil {
+		response.Error(w, apperr.Internal("Failed to delete inventory log", err))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]string{"message": "Inventory log deleted successfully"})
+	response.Message(w, "Inventory log deleted successfully")
 }
@@ -0,0 +1,29 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/listquery"
+	"backend/internal/response"
+	"errors"
+	"net/http"
+)
+
+// listParams đọc page / per_page / cursor / sort / filter từ query string.
+// Tham số sai -> 422 kèm danh sách field lỗi.
+func listParams(w http.ResponseWriter, r *http.Request) (listquery.Params, bool) {
+	p, err := listquery.FromQuery(r.URL.Query())
+	if err != nil {
+		response.Error(w, err)
+		return p, false
+	}
+	return p, true
+}
+
+// listError giữ lỗi 422 của listquery (sort / filter / cursor sai), lỗi khác -> 500
+func listError(err error, failMsg string) error {
+	var appErr *apperr.Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return apperr.Internal(failMsg, err)
+}
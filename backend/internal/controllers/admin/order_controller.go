@@ -2,77 +2,85 @@
 package admin
 
 import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
 	orderRepo "backend/internal/repository/admin"
-	 "backend/internal/models"
-    "backend/configs"
-	"encoding/json"
+	"backend/internal/response"
+	"backend/internal/validate"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 )
 
-// GET ALL ORDERS
-func GetAllOrders(w http.ResponseWriter, r *http.Request) {
-	status := r.URL.Query().Get("status")
+// OrderController xử lý các API admin về order
+type OrderController struct {
+	orders orderRepo.OrderRepository
+}
 
-	var orders []models.Order
-	query := configs.DB.
-		Preload("Customer").
-		Preload("Staff").
-		Preload("Items.Variant.Product")
+func NewOrderController(orders orderRepo.OrderRepository) *OrderController {
+	return &OrderController{orders: orders}
+}
 
-	if status != "" {
-		query = query.Where("status = ?", status)
+// GET ALL ORDERS
+func (c *OrderController) GetAllOrders(w http.ResponseWriter, r *http.Request) {
+	params, ok := listParams(w, r)
+	if !ok {
+		return
 	}
 
-	if err := query.Find(&orders).Error; err != nil {
-		http.Error(w, "Failed to fetch orders", http.StatusInternalServerError)
+	orders, meta, err := c.orders.GetAllOrders(params)
+	if err != nil {
+		response.Error(w, listError(err, "Failed to fetch orders"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"data": orders})
+	response.List(w, orders, meta)
 }
 
 // GET ORDER DETAIL
-func GetOrderDetail(w http.ResponseWriter, r *http.Request) {
+func (c *OrderController) GetOrderDetail(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid order ID"))
 		return
 	}
-	order, err := orderRepo.GetOrderDetail(uint(id))
+	order, err := c.orders.GetOrderDetail(uint(id))
 	if err != nil {
-		http.Error(w, "Order not found", http.StatusNotFound)
+		response.Error(w, apperr.FromRepo(err, "Order not found", "Failed to fetch order"))
 		return
 	}
-	json.NewEncoder(w).Encode(order)
+	response.OK(w, order)
 }
 
-// UPDATE ORDER STATUS
-func UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+// UPDATE ORDER STATUS (staff phụ trách là user đang đăng nhập, lấy từ JWT)
+func (c *OrderController) UpdateOrderStatus(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid order ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid order ID"))
 		return
 	}
 
 	var body struct {
-		Status  string `json:"status"`
-		StaffID *uint  `json:"staff_id"`
+		Status string `json:"status" validate:"required,enum=order_status"`
+		Note   string `json:"note"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	if err := orderRepo.UpdateOrderStatus(uint(id), body.Status, body.StaffID); err != nil {
-		http.Error(w, "Failed to update order", http.StatusInternalServerError)
+	if err := c.orders.UpdateOrderStatus(uint(id), body.Status, claims, body.Note); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Order not found", "Failed to update order"))
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]string{"message": "Order status updated"})
+	response.Message(w, "Order status updated")
 }
@@ -1,157 +1,336 @@
 package admin
 
 import (
+	"backend/internal/apperr"
 	"backend/internal/models"
 	admin "backend/internal/repository/admin"
-	"encoding/json"
+	"backend/internal/response"
+	"backend/internal/utils"
+	"backend/internal/validate"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
 
+// ProductController xử lý các API admin về product + variant
+type ProductController struct {
+	products admin.ProductRepository
+}
+
+func NewProductController(products admin.ProductRepository) *ProductController {
+	return &ProductController{products: products}
+}
+
+// productRequest: body tạo / sửa product; id, version, is_published... không nhận từ client
+type productRequest struct {
+	Name        string  `json:"name" validate:"required,max=255"`
+	Description string  `json:"description"`
+	CategoryID  uint    `json:"category_id" validate:"required"`
+	Image       string  `json:"image" validate:"max=255"`
+	Price       float64 `json:"price" validate:"min=0"`
+	Discount    float64 `json:"discount" validate:"min=0,max=100"`
+}
+
+func (req productRequest) model() *models.Product {
+	return &models.Product{
+		Name:        strings.TrimSpace(req.Name),
+		Description: req.Description,
+		CategoryID:  req.CategoryID,
+		Image:       req.Image,
+		Price:       req.Price,
+		Discount:    req.Discount,
+	}
+}
+
+// variantRequest: body tạo / sửa variant; product lấy từ URL
+type variantRequest struct {
+	Size  string  `json:"size" validate:"max=50"`
+	Color string  `json:"color" validate:"max=50"`
+	Price float64 `json:"price" validate:"min=0"` // 0 = dùng giá của product
+	Stock int     `json:"stock" validate:"min=0"`
+	SKU   string  `json:"sku" validate:"required,max=100"`
+	Image string  `json:"image" validate:"max=255"`
+}
+
+func (req variantRequest) model(productID uint) *models.ProductVariant {
+	return &models.ProductVariant{
+		ProductID: productID,
+		Size:      req.Size,
+		Color:     req.Color,
+		Price:     req.Price,
+		Stock:     req.Stock,
+		SKU:       strings.TrimSpace(req.SKU),
+		Image:     req.Image,
+	}
+}
+
 // ================= PRODUCTS ==================
 
 // GET ALL PRODUCTS
-func GetAllProducts(w http.ResponseWriter, r *http.Request) {
-	products, err := admin.GetAllProducts()
+func (c *ProductController) GetAllProducts(w http.ResponseWriter, r *http.Request) {
+	params, ok := listParams(w, r)
+	if !ok {
+		return
+	}
+	products, meta, err := c.products.GetAllProducts(params)
 	if err != nil {
-		http.Error(w, "Failed to fetch products", http.StatusInternalServerError)
+		response.Error(w, listError(err, "Failed to fetch products"))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]interface{}{"data": products})
+	response.List(w, products, meta)
 }
 
 // GET PRODUCT DETAIL
-func GetProductDetail(w http.ResponseWriter, r *http.Request) {
+func (c *ProductController) GetProductDetail(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
 		return
 	}
-	product, err := admin.GetProductDetail(uint(id))
+	product, err := c.products.GetProductDetail(uint(id))
 	if err != nil {
-		http.Error(w, "Product not found", http.StatusNotFound)
+		response.Error(w, apperr.FromRepo(err, "Product not found", "Failed to fetch product"))
 		return
 	}
-	json.NewEncoder(w).Encode(product)
+	utils.SetETag(w, product.Version)
+	response.OK(w, product)
 }
 
 // CREATE PRODUCT
-// CREATE PRODUCT
-func CreateProduct(w http.ResponseWriter, r *http.Request) {
-	var req models.Product
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (c *ProductController) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	var req productRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	product, err := admin.CreateProduct(&req)
+	product, err := c.products.CreateProduct(req.model())
 	if err != nil {
-		http.Error(w, "Failed to create product", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to create product", err))
 		return
 	}
-	json.NewEncoder(w).Encode(product)
+	response.OK(w, product)
 }
 
-// UPDATE PRODUCT
-func EditProduct(w http.ResponseWriter, r *http.Request) {
+// UPDATE PRODUCT (bắt buộc If-Match)
+func (c *ProductController) EditProduct(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
-	id, _ := strconv.Atoi(idParam)
-	var req models.Product
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	var req productRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	product, err := admin.UpdateProduct(uint(id), &req)
+	product, err := c.products.UpdateProduct(uint(id), req.model(), version)
 	if err != nil {
-		http.Error(w, "Failed to update product", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Product not found", "Failed to update product"))
 		return
 	}
-	json.NewEncoder(w).Encode(product)
+	utils.SetETag(w, product.Version)
+	response.OK(w, product)
+}
+
+// PUBLISH / UNPUBLISH PRODUCT
+func (c *ProductController) PublishProduct(w http.ResponseWriter, r *http.Request) {
+	idParam := mux.Vars(r)["id"]
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return
+	}
+	var body struct {
+		IsPublished bool `json:"is_published"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+	product, err := c.products.SetProductPublished(uint(id), body.IsPublished)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to update product", err))
+		return
+	}
+	utils.SetETag(w, product.Version)
+	response.OK(w, product)
 }
 
 // DELETE PRODUCT
-func DeleteProduct(w http.ResponseWriter, r *http.Request) {
+func (c *ProductController) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
-	id, _ := strconv.Atoi(idParam)
-	if err := admin.DeleteProduct(uint(id)); err != nil {
-		http.Error(w, "Failed to delete product", http.StatusInternalServerError)
+	id, err := strconv.Atoi(idParam)
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return
+	}
+	if err := c.products.DeleteProduct(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Product not found", "Failed to delete product"))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]string{"message": "Product deleted successfully"})
+	response.Message(w, "Product deleted successfully")
+}
+
+// GET TRASHED PRODUCTS (đã soft-delete)
+func (c *ProductController) GetTrashedProducts(w http.ResponseWriter, r *http.Request) {
+	items, err := c.products.GetTrashedProducts()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch trashed products", err))
+		return
+	}
+	response.OK(w, items)
+}
+
+// RESTORE PRODUCT từ thùng rác
+func (c *ProductController) RestoreProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return
+	}
+	if err := c.products.RestoreProduct(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Product not found in trash", "Failed to restore product"))
+		return
+	}
+	response.Message(w, "Product restored successfully")
+}
+
+// PURGE PRODUCT: xóa vĩnh viễn (chỉ bản ghi đã ở thùng rác)
+func (c *ProductController) PurgeProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return
+	}
+	if err := c.products.PurgeProduct(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Product not found in trash", "Failed to purge product"))
+		return
+	}
+	response.Message(w, "Product permanently deleted")
 }
 
 // ================= VARIANTS ==================
-func GetVariantsByProduct(w http.ResponseWriter, r *http.Request) {
+func (c *ProductController) GetVariantsByProduct(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
 	productID, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
 		return
 	}
 
-	variants, err := admin.GetVariantsByProduct(uint(productID))
+	variants, err := c.products.GetVariantsByProduct(uint(productID))
 	if err != nil {
-		http.Error(w, "Failed to fetch variants", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to fetch variants", err))
 		return
 	}
 
-	json.NewEncoder(w).Encode(map[string]interface{}{"data": variants})
+	response.OK(w, variants)
 }
-func GetAllVariants(w http.ResponseWriter, r *http.Request) {
-	variants, err := admin.GetAllVariants()
+func (c *ProductController) GetAllVariants(w http.ResponseWriter, r *http.Request) {
+	variants, err := c.products.GetAllVariants()
 	if err != nil {
-		http.Error(w, "Failed to fetch variants", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to fetch variants", err))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(variants) // <-- trả về [] luôn, không bọc "data"
+	response.OK(w, variants)
+}
+
+// GET VARIANT DETAIL (trả ETag để dùng cho If-Match khi sửa)
+func (c *ProductController) GetVariantDetail(w http.ResponseWriter, r *http.Request) {
+	productID, id, ok := variantIDs(w, r)
+	if !ok {
+		return
+	}
+	variant, err := c.products.GetVariantDetail(productID, id)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Variant not found", "Failed to fetch variant"))
+		return
+	}
+	utils.SetETag(w, variant.Version)
+	response.OK(w, variant)
 }
 
 // CREATE VARIANT
-func CreateVariant(w http.ResponseWriter, r *http.Request) {
-	var req models.ProductVariant
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (c *ProductController) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	productID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return
+	}
+	var req variantRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 	// req.Image sẽ nhận giá trị từ FE
-	variant, err := admin.CreateVariant(&req)
+	variant, err := c.products.CreateVariant(req.model(uint(productID)))
 	if err != nil {
-		http.Error(w, "Failed to create variant", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to create variant", err))
 		return
 	}
-	json.NewEncoder(w).Encode(variant)
+	response.OK(w, variant)
 }
 
-// UPDATE VARIANT
-func EditVariant(w http.ResponseWriter, r *http.Request) {
-	idParam := mux.Vars(r)["id"]
-	id, _ := strconv.Atoi(idParam)
-	var req models.ProductVariant
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+// UPDATE VARIANT (bắt buộc If-Match)
+func (c *ProductController) EditVariant(w http.ResponseWriter, r *http.Request) {
+	productID, id, ok := variantIDs(w, r)
+	if !ok {
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
+		return
+	}
+	var req variantRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 	// req.Image sẽ nhận giá trị từ FE
-	variant, err := admin.UpdateVariant(uint(id), &req)
+	variant, err := c.products.UpdateVariant(productID, id, req.model(productID), version)
 	if err != nil {
-		http.Error(w, "Failed to update variant", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Variant not found", "Failed to update variant"))
 		return
 	}
-	json.NewEncoder(w).Encode(variant)
+	utils.SetETag(w, variant.Version)
+	response.OK(w, variant)
 }
 
 // DELETE VARIANT
-func DeleteVariant(w http.ResponseWriter, r *http.Request) {
-	idParam := mux.Vars(r)["id"]
-	id, _ := strconv.Atoi(idParam)
-	if err := admin.DeleteVariant(uint(id)); err != nil {
-		http.Error(w, "Failed to delete variant", http.StatusInternalServerError)
+func (c *ProductController) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	productID, id, ok := variantIDs(w, r)
+	if !ok {
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]string{"message": "Variant deleted successfully"})
+	if err := c.products.DeleteVariant(productID, id); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Variant not found", "Failed to delete variant"))
+		return
+	}
+	response.Message(w, "Variant deleted successfully")
+}
+
+// variantIDs đọc {id} (product) và {variantId} trên URL; id không hợp lệ -> 400
+func variantIDs(w http.ResponseWriter, r *http.Request) (productID, id uint, ok bool) {
+	vars := mux.Vars(r)
+	pid, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return 0, 0, false
+	}
+	vid, err := strconv.Atoi(vars["variantId"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid variant ID"))
+		return 0, 0, false
+	}
+	return uint(pid), uint(vid), true
 }
@@ -0,0 +1,186 @@
+package admin
+
+import (
+	"backend/internal/listquery"
+	"backend/internal/models"
+	adminRepo "backend/internal/repository/admin"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// fakeProducts thay ProductRepository trong test controller, không cần DB.
+// Method không được override sẽ panic (interface nhúng là nil).
+type fakeProducts struct {
+	adminRepo.ProductRepository
+
+	products map[uint]*models.Product
+	params   listquery.Params
+	updated  *models.Product
+	version  uint
+}
+
+func (f *fakeProducts) GetAllProducts(p listquery.Params) ([]models.Product, *listquery.Meta, error) {
+	f.params = p
+	var list []models.Product
+	for _, product := range f.products {
+		list = append(list, *product)
+	}
+	return list, &listquery.Meta{Total: int64(len(list)), Page: p.Page, PerPage: p.PerPage}, nil
+}
+
+func (f *fakeProducts) GetProductDetail(id uint) (*models.Product, error) {
+	product, ok := f.products[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return product, nil
+}
+
+func (f *fakeProducts) UpdateProduct(id uint, newData *models.Product, version uint) (*models.Product, error) {
+	product, ok := f.products[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if version != product.Version {
+		return nil, adminRepo.ErrVersionConflict
+	}
+	f.updated, f.version = newData, version
+	updated := *newData
+	updated.ID, updated.Version = id, version+1
+	return &updated, nil
+}
+
+func newProductTest() (*fakeProducts, *mux.Router) {
+	fake := &fakeProducts{products: map[uint]*models.Product{
+		1: {ID: 1, Name: "Áo thun", CategoryID: 2, Version: 3},
+	}}
+	c := NewProductController(fake)
+	r := mux.NewRouter()
+	r.HandleFunc("/products", c.GetAllProducts).Methods("GET")
+	r.HandleFunc("/products/{id:[0-9]+}", c.GetProductDetail).Methods("GET")
+	r.HandleFunc("/products/{id:[0-9]+}", c.EditProduct).Methods("PUT")
+	return fake, r
+}
+
+func do(r http.Handler, method, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// errorCode đọc error.code trong envelope lỗi
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return body.Error.Code
+}
+
+func TestGetAllProductsPassesListParams(t *testing.T) {
+	fake, r := newProductTest()
+
+	w := do(r, "GET", "/products?page=2&per_page=5&sort=-price&category_id=2", "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if fake.params.Page != 2 || fake.params.PerPage != 5 || fake.params.Sort != "-price" || fake.params.Values.Get("category_id") != "2" {
+		t.Errorf("params = %+v", fake.params)
+	}
+	var body struct {
+		Data []models.Product `json:"data"`
+		Meta listquery.Meta   `json:"meta"`
+	}
+	json.NewDecoder(w.Body).Decode(&body)
+	if len(body.Data) != 1 || body.Meta.Total != 1 || body.Meta.PerPage != 5 {
+		t.Errorf("body = %+v", body)
+	}
+
+	// Tham số phân trang sai bị chặn trước khi gọi repository
+	fake.params = listquery.Params{}
+	if w := do(r, "GET", "/products?per_page=1000", "", nil); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("per_page=1000: status = %d, want 422", w.Code)
+	}
+	if fake.params.PerPage != 0 {
+		t.Error("repository called with invalid params")
+	}
+}
+
+func TestGetProductDetail(t *testing.T) {
+	_, r := newProductTest()
+
+	w := do(r, "GET", "/products/1", "", nil)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != `"3"` {
+		t.Errorf("status = %d, ETag = %q, want 200 \"3\"", w.Code, w.Header().Get("ETag"))
+	}
+
+	w = do(r, "GET", "/products/9", "", nil)
+	if w.Code != http.StatusNotFound || errorCode(t, w) != "not_found" {
+		t.Errorf("missing product: status = %d, want 404 not_found", w.Code)
+	}
+}
+
+func TestEditProduct(t *testing.T) {
+	body := `{"name":"  Áo polo ","category_id":2,"price":100}`
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		ifMatch  string
+		status   int
+		code     string
+		wantCall bool
+	}{
+		{"updated", "/products/1", body, `"3"`, http.StatusOK, "", true},
+		{"missing If-Match", "/products/1", body, "", http.StatusPreconditionRequired, "precondition_required", false},
+		{"stale version", "/products/1", body, `"2"`, http.StatusPreconditionFailed, "version_conflict", false},
+		{"unknown product", "/products/9", body, `"3"`, http.StatusNotFound, "not_found", false},
+		{"id out of range", "/products/99999999999999999999", body, `"3"`, http.StatusBadRequest, "bad_request", false},
+		{"invalid body", "/products/1", `{"name":"","category_id":2}`, `"3"`, http.StatusUnprocessableEntity, "validation_failed", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, r := newProductTest()
+			header := map[string]string{}
+			if tt.ifMatch != "" {
+				header["If-Match"] = tt.ifMatch
+			}
+
+			w := do(r, "PUT", tt.path, tt.body, header)
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if tt.code != "" {
+				if code := errorCode(t, w); code != tt.code {
+					t.Errorf("code = %q, want %q", code, tt.code)
+				}
+			}
+			if got := fake.updated != nil; got != tt.wantCall {
+				t.Fatalf("repository updated = %v, want %v", got, tt.wantCall)
+			}
+			if tt.wantCall {
+				if fake.updated.Name != "Áo polo" || fake.version != 3 {
+					t.Errorf("updated = %+v, version = %d", fake.updated, fake.version)
+				}
+				if w.Header().Get("ETag") != `"4"` {
+					t.Errorf("ETag = %q, want \"4\"", w.Header().Get("ETag"))
+				}
+			}
+		})
+	}
+}
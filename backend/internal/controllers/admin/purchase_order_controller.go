@@ -1,158 +1,203 @@
 package admin
 
 import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
 	"backend/internal/models"
 	admin "backend/internal/repository/admin"
-	"encoding/json"
+	"backend/internal/response"
+	"backend/internal/validate"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 )
 
+// PurchaseController xử lý các API admin về purchase
+type PurchaseController struct {
+	purchases admin.PurchaseRepository
+}
+
+func NewPurchaseController(purchases admin.PurchaseRepository) *PurchaseController {
+	return &PurchaseController{purchases: purchases}
+}
+
+// purchaseRequest: body tạo / sửa phiếu nhập; total do DB tính, supplier lấy từ URL khi có,
+// staff là user đang đăng nhập (lấy từ JWT)
+type purchaseRequest struct {
+	VariantID uint    `json:"variant_id" validate:"required"`
+	Quantity  int     `json:"quantity" validate:"gt=0"`
+	CostPrice float64 `json:"cost_price" validate:"min=0"`
+}
+
+// globalPurchaseRequest: tạo phiếu nhập qua /purchases phải kèm supplier_id
+type globalPurchaseRequest struct {
+	SupplierID uint `json:"supplier_id" validate:"required"`
+	purchaseRequest
+}
+
+func (req purchaseRequest) model(supplierID, staffID uint) *models.Purchase {
+	return &models.Purchase{
+		SupplierID: supplierID,
+		StaffID:    staffID,
+		VariantID:  req.VariantID,
+		Quantity:   req.Quantity,
+		CostPrice:  req.CostPrice,
+	}
+}
+
 // ---------- Global purchases (optional) ----------
 
 // GET /api/admin/purchases
-func GetAllPurchasesGlobal(w http.ResponseWriter, r *http.Request) {
-    purchases, err := admin.GetAllPurchases()
-    if err != nil {
-        http.Error(w, "Failed to fetch purchases", http.StatusInternalServerError)
-        return
-    }
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(purchases) // <-- trả về [] luôn, không bọc "data"
+func (c *PurchaseController) GetAllPurchasesGlobal(w http.ResponseWriter, r *http.Request) {
+	params, ok := listParams(w, r)
+	if !ok {
+		return
+	}
+	purchases, meta, err := c.purchases.GetAllPurchases(params)
+	if err != nil {
+		response.Error(w, listError(err, "Failed to fetch purchases"))
+		return
+	}
+	response.List(w, purchases, meta)
 }
 
 // POST /api/admin/purchases  (body must include supplier_id)
-func CreatePurchaseGlobal(w http.ResponseWriter, r *http.Request) {
-	var req models.Purchase
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (c *PurchaseController) CreatePurchaseGlobal(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
 		return
 	}
-	if req.SupplierID == 0 {
-		http.Error(w, "supplier_id required", http.StatusBadRequest)
+	var req globalPurchaseRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
-	purchase, err := admin.CreatePurchase(&req)
+	purchase, err := c.purchases.CreatePurchase(req.model(req.SupplierID, claims.UserID))
 	if err != nil {
-		http.Error(w, "Failed to create purchase", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Purchase not found", "Failed to create purchase"))
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(purchase)
+	response.Created(w, purchase)
 }
 
 // PUT /api/admin/purchases/{id}
-func EditPurchaseGlobal(w http.ResponseWriter, r *http.Request) {
+func (c *PurchaseController) EditPurchaseGlobal(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid id", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid id"))
 		return
 	}
-	var req models.Purchase
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	var req purchaseRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
-	purchase, err := admin.UpdatePurchase(uint(id), &req)
+	purchase, err := c.purchases.UpdatePurchase(uint(id), req.model(0, 0))
 	if err != nil {
-		http.Error(w, "Failed to update purchase", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Purchase not found", "Failed to update purchase"))
 		return
 	}
-	json.NewEncoder(w).Encode(purchase)
+	response.OK(w, purchase)
 }
 
 // DELETE /api/admin/purchases/{id}
-func DeletePurchaseGlobal(w http.ResponseWriter, r *http.Request) {
+func (c *PurchaseController) DeletePurchaseGlobal(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid id", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid id"))
 		return
 	}
-	if err := admin.DeletePurchase(uint(id)); err != nil {
-		http.Error(w, "Failed to delete purchase", http.StatusInternalServerError)
+	if err := c.purchases.DeletePurchase(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Purchase not found", "Failed to delete purchase"))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]string{"message": "Purchase deleted"})
+	response.Message(w, "Purchase deleted")
 }
 
 // ---------- Supplier-scoped purchases ----------
 
 // GET /api/admin/suppliers/{id}/purchases
-func GetPurchasesBySupplier(w http.ResponseWriter, r *http.Request) {
+func (c *PurchaseController) GetPurchasesBySupplier(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["id"]
 	sid, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid supplier ID"))
+		return
+	}
+	params, ok := listParams(w, r)
+	if !ok {
 		return
 	}
-	purchases, err := admin.GetPurchasesBySupplier(uint(sid))
+	purchases, meta, err := c.purchases.GetPurchasesBySupplier(uint(sid), params)
 	if err != nil {
-		http.Error(w, "Failed to fetch purchases", http.StatusInternalServerError)
+		response.Error(w, listError(err, "Failed to fetch purchases"))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{"data": purchases})
+	response.List(w, purchases, meta)
 }
 
 // POST /api/admin/suppliers/{id}/purchases
-func CreatePurchaseForSupplier(w http.ResponseWriter, r *http.Request) {
+func (c *PurchaseController) CreatePurchaseForSupplier(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["id"]
 	sid, err := strconv.Atoi(idStr)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid supplier ID"))
+		return
+	}
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
 		return
 	}
-	var req models.Purchase
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	var req purchaseRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
-	req.SupplierID = uint(sid)
-	purchase, err := admin.CreatePurchase(&req)
+	purchase, err := c.purchases.CreatePurchase(req.model(uint(sid), claims.UserID))
 	if err != nil {
-		http.Error(w, "Failed to create purchase", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Purchase not found", "Failed to create purchase"))
 		return
 	}
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(purchase)
+	response.Created(w, purchase)
 }
 
 // PUT /api/admin/suppliers/{id}/purchases/{purchaseId}
-func EditPurchaseForSupplier(w http.ResponseWriter, r *http.Request) {
+func (c *PurchaseController) EditPurchaseForSupplier(w http.ResponseWriter, r *http.Request) {
 	pidStr := mux.Vars(r)["purchaseId"]
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil {
-		http.Error(w, "Invalid purchase ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid purchase ID"))
 		return
 	}
-	var req models.Purchase
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	var req purchaseRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
-	purchase, err := admin.UpdatePurchase(uint(pid), &req)
+	purchase, err := c.purchases.UpdatePurchase(uint(pid), req.model(0, 0))
 	if err != nil {
-		http.Error(w, "Failed to update purchase", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Purchase not found", "Failed to update purchase"))
 		return
 	}
-	json.NewEncoder(w).Encode(purchase)
+	response.OK(w, purchase)
 }
 
 // DELETE /api/admin/suppliers/{id}/purchases/{purchaseId}
-func DeletePurchaseForSupplier(w http.ResponseWriter, r *http.Request) {
+func (c *PurchaseController) DeletePurchaseForSupplier(w http.ResponseWriter, r *http.Request) {
 	pidStr := mux.Vars(r)["purchaseId"]
 	pid, err := strconv.Atoi(pidStr)
 	if err != nil {
-		http.Error(w, "Invalid purchase ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid purchase ID"))
 		return
 	}
-	if err := admin.DeletePurchase(uint(pid)); err != nil {
-		http.Error(w, "Failed to delete purchase", http.StatusInternalServerError)
+	if err := c.purchases.DeletePurchase(uint(pid)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Purchase not found", "Failed to delete purchase"))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]string{"message": "Purchase deleted"})
+	response.Message(w, "Purchase deleted")
 }
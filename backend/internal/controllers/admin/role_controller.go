@@ -0,0 +1,144 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+	adminRepo "backend/internal/repository/admin"
+	"backend/internal/response"
+	"backend/internal/validate"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// RoleController xử lý các API admin về role + permission
+type RoleController struct {
+	roles adminRepo.RoleRepository
+}
+
+func NewRoleController(roles adminRepo.RoleRepository) *RoleController {
+	return &RoleController{roles: roles}
+}
+
+// createRoleRequest: tạo role bắt buộc có tên
+type createRoleRequest struct {
+	Name        string   `json:"name" validate:"required,max=50"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// updateRoleRequest: field không gửi (nil) được giữ nguyên
+type updateRoleRequest struct {
+	Name        *string   `json:"name" validate:"notblank,max=50"`
+	Description *string   `json:"description"`
+	Permissions *[]string `json:"permissions"`
+}
+
+// GET /api/admin/roles
+func (c *RoleController) GetAllRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := c.roles.GetAllRoles()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch roles", err))
+		return
+	}
+	response.OK(w, roles)
+}
+
+// GET /api/admin/roles/{id}
+func (c *RoleController) GetRoleDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid role ID"))
+		return
+	}
+	role, err := c.roles.GetRoleDetail(uint(id))
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Role not found", "Failed to fetch role"))
+		return
+	}
+	response.OK(w, role)
+}
+
+// POST /api/admin/roles
+func (c *RoleController) CreateRole(w http.ResponseWriter, r *http.Request) {
+	var req createRoleRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+	role, err := c.roles.CreateRole(req.Name, req.Description, req.Permissions)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Role not found", "Failed to create role"))
+		return
+	}
+	response.Created(w, role)
+}
+
+// PUT /api/admin/roles/{id}
+func (c *RoleController) EditRole(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid role ID"))
+		return
+	}
+	var req updateRoleRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+	role, err := c.roles.UpdateRole(uint(id), adminRepo.RoleUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: req.Permissions,
+	})
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Role not found", "Failed to update role"))
+		return
+	}
+	response.OK(w, role)
+}
+
+// DELETE /api/admin/roles/{id}
+func (c *RoleController) DeleteRole(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid role ID"))
+		return
+	}
+	if err := c.roles.DeleteRole(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Role not found", "Failed to delete role"))
+		return
+	}
+	response.Message(w, "Role deleted successfully")
+}
+
+// GET /api/admin/permissions
+func (c *RoleController) GetAllPermissions(w http.ResponseWriter, r *http.Request) {
+	perms, err := c.roles.GetAllPermissions()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch permissions", err))
+		return
+	}
+	response.OK(w, perms)
+}
+
+// PUT /api/admin/users/{id}/roles
+func (c *RoleController) SetUserRoles(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
+		return
+	}
+	var body struct {
+		RoleIDs []uint `json:"role_ids"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+	if err := c.roles.SetUserRoles(uint(id), body.RoleIDs); err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to update user roles"))
+		return
+	}
+	response.Message(w, "User roles updated")
+}
@@ -1,22 +1,31 @@
 package admin
 
 import (
-    "encoding/json"
-    "net/http"
+    "backend/internal/apperr"
     "backend/internal/repository/admin"
+    "backend/internal/response"
+    "net/http"
 )
 
-func SearchAll(w http.ResponseWriter, r *http.Request) {
+// SearchController xử lý API tìm kiếm nhanh của admin
+type SearchController struct {
+    search admin.SearchRepository
+}
+
+func NewSearchController(search admin.SearchRepository) *SearchController {
+    return &SearchController{search: search}
+}
+
+func (c *SearchController) SearchAll(w http.ResponseWriter, r *http.Request) {
     keyword := r.URL.Query().Get("q")
     if len(keyword) == 0 {
-        json.NewEncoder(w).Encode([]interface{}{}) // trả về mảng rỗng nếu không có keyword
+        response.OK(w, []interface{}{}) // trả về mảng rỗng nếu không có keyword
         return
     }
-    results, err := admin.SearchAll(keyword)
+    results, err := c.search.SearchAll(keyword)
     if err != nil {
-        http.Error(w, "Search failed", http.StatusInternalServerError)
+        response.Error(w, apperr.Internal("Search failed", err))
         return
     }
-    w.Header().Set("Content-Type", "application/json")
-    json.NewEncoder(w).Encode(results)
+    response.OK(w, results)
 }
\ No newline at end of file
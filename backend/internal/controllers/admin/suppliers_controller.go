@@ -1,88 +1,162 @@
 package admin
 
 import (
+	"backend/internal/apperr"
 	"backend/internal/models"
 	admin "backend/internal/repository/admin"
-	"encoding/json"
+	"backend/internal/response"
+	"backend/internal/utils"
+	"backend/internal/validate"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
 
+// SupplierController xử lý các API admin về supplier
+type SupplierController struct {
+	suppliers admin.SupplierRepository
+}
+
+func NewSupplierController(suppliers admin.SupplierRepository) *SupplierController {
+	return &SupplierController{suppliers: suppliers}
+}
+
+// supplierRequest: body tạo / sửa supplier
+type supplierRequest struct {
+	Name    string `json:"name" validate:"required,max=255"`
+	Phone   string `json:"phone" validate:"max=20"`
+	Email   string `json:"email" validate:"omitempty,email"`
+	Address string `json:"address" validate:"max=255"`
+}
+
+func (req supplierRequest) model() *models.Supplier {
+	return &models.Supplier{
+		Name:    strings.TrimSpace(req.Name),
+		Phone:   strings.TrimSpace(req.Phone),
+		Email:   strings.TrimSpace(req.Email),
+		Address: req.Address,
+	}
+}
+
 // GET ALL SUPPLIERS
-func GetAllSuppliers(w http.ResponseWriter, r *http.Request) {
-	suppliers, err := admin.GetAllSuppliers()
+func (c *SupplierController) GetAllSuppliers(w http.ResponseWriter, r *http.Request) {
+	suppliers, err := c.suppliers.GetAllSuppliers()
 	if err != nil {
-		http.Error(w, "Failed to fetch suppliers", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to fetch suppliers", err))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]interface{}{"data": suppliers})
+	response.OK(w, suppliers)
 }
 
 // GET SUPPLIER DETAIL (kèm theo purchases)
-func GetSupplierDetail(w http.ResponseWriter, r *http.Request) {
+func (c *SupplierController) GetSupplierDetail(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid supplier ID"))
 		return
 	}
-	supplier, err := admin.GetSupplierDetail(uint(id))
+	supplier, err := c.suppliers.GetSupplierDetail(uint(id))
 	if err != nil {
-		http.Error(w, "Supplier not found", http.StatusNotFound)
+		response.Error(w, apperr.FromRepo(err, "Supplier not found", "Failed to fetch supplier"))
 		return
 	}
-	json.NewEncoder(w).Encode(supplier)
+	utils.SetETag(w, supplier.Version)
+	response.OK(w, supplier)
 }
 
 // CREATE SUPPLIER
-func CreateSupplier(w http.ResponseWriter, r *http.Request) {
-	var req models.Supplier
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+func (c *SupplierController) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	var req supplierRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
-	supplier, err := admin.CreateSupplier(&req)
+	supplier, err := c.suppliers.CreateSupplier(req.model())
 	if err != nil {
-		http.Error(w, "Failed to create supplier", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to create supplier", err))
 		return
 	}
-	json.NewEncoder(w).Encode(supplier)
+	response.OK(w, supplier)
 }
 
-// UPDATE SUPPLIER
-func EditSupplier(w http.ResponseWriter, r *http.Request) {
+// UPDATE SUPPLIER (bắt buộc If-Match)
+func (c *SupplierController) EditSupplier(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid supplier ID"))
+		return
+	}
+	version, ok := requireIfMatch(w, r)
+	if !ok {
 		return
 	}
-	var req models.Supplier
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	var req supplierRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
-	supplier, err := admin.UpdateSupplier(uint(id), &req)
+	supplier, err := c.suppliers.UpdateSupplier(uint(id), req.model(), version)
 	if err != nil {
-		http.Error(w, "Failed to update supplier", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "Supplier not found", "Failed to update supplier"))
 		return
 	}
-	json.NewEncoder(w).Encode(supplier)
+	utils.SetETag(w, supplier.Version)
+	response.OK(w, supplier)
 }
 
 // DELETE SUPPLIER
-func DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+func (c *SupplierController) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
 	idParam := mux.Vars(r)["id"]
 	id, err := strconv.Atoi(idParam)
 	if err != nil {
-		http.Error(w, "Invalid supplier ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid supplier ID"))
+		return
+	}
+	if err := c.suppliers.DeleteSupplier(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Supplier not found", "Failed to delete supplier"))
+		return
+	}
+	response.Message(w, "Supplier deleted successfully")
+}
+
+// GET TRASHED SUPPLIERS (đã soft-delete)
+func (c *SupplierController) GetTrashedSuppliers(w http.ResponseWriter, r *http.Request) {
+	items, err := c.suppliers.GetTrashedSuppliers()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch trashed suppliers", err))
+		return
+	}
+	response.OK(w, items)
+}
+
+// RESTORE SUPPLIER từ thùng rác
+func (c *SupplierController) RestoreSupplier(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid supplier ID"))
+		return
+	}
+	if err := c.suppliers.RestoreSupplier(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Supplier not found in trash", "Failed to restore supplier"))
+		return
+	}
+	response.Message(w, "Supplier restored successfully")
+}
+
+// PURGE SUPPLIER: xóa vĩnh viễn (chỉ bản ghi đã ở thùng rác)
+func (c *SupplierController) PurgeSupplier(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid supplier ID"))
 		return
 	}
-	if err := admin.DeleteSupplier(uint(id)); err != nil {
-		http.Error(w, "Failed to delete supplier", http.StatusInternalServerError)
+	if err := c.suppliers.PurgeSupplier(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Supplier not found in trash", "Failed to purge supplier"))
 		return
 	}
-	json.NewEncoder(w).Encode(map[string]string{"message": "Supplier deleted successfully"})
+	response.Message(w, "Supplier permanently deleted")
 }
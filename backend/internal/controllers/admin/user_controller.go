@@ -1,94 +1,254 @@
 package admin
 
 import (
-	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/listquery"
 	"backend/internal/middlewares"
 	"backend/internal/models"
 	adminRepo "backend/internal/repository/admin"
-	"encoding/json"
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/internal/utils"
+	"backend/internal/validate"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 )
 
+// UserController xử lý các API admin về user
+type UserController struct {
+	users adminRepo.UserRepository
+}
+
+func NewUserController(users adminRepo.UserRepository) *UserController {
+	return &UserController{users: users}
+}
+
+// userRequest: body sửa thông tin user; role đổi qua EditUserRole, id / created_at không nhận từ client
+type userRequest struct {
+	Username string `json:"username" validate:"required,max=255"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Phone    string `json:"phone" validate:"max=20"`
+	Address  string `json:"address" validate:"max=255"`
+}
+
+// userRoleRequest: body đổi role chính của user
+type userRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
 // GET ALL USERS
-func GetAllUsers(w http.ResponseWriter, r *http.Request) {
-	users, err := adminRepo.GetAllUsers()
+func (c *UserController) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	params, ok := listParams(w, r)
+	if !ok {
+		return
+	}
+	users, meta, err := c.users.GetAllUsers(params)
 	if err != nil {
-		http.Error(w, "Failed to fetch users", http.StatusInternalServerError)
+		response.Error(w, listError(err, "Failed to fetch users"))
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(users)
+	response.List(w, users, meta)
 }
 
 // EDIT USER
-func EditUser(w http.ResponseWriter, r *http.Request) {
+func (c *UserController) EditUser(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
+		return
+	}
+
+	var req userRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := c.users.UpdateUser(uint(id), &models.User{
+		Username: strings.TrimSpace(req.Username),
+		Email:    strings.TrimSpace(req.Email),
+		Phone:    strings.TrimSpace(req.Phone),
+		Address:  req.Address,
+	})
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to update user"))
+		return
+	}
+
+	response.OK(w, user)
+}
+
+// UPDATE USER ROLE (admin/staff/customer)
+func (c *UserController) EditUserRole(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
 		return
 	}
 
-	var req models.User
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	var body userRoleRequest
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	user, err := adminRepo.UpdateUser(uint(id), &req)
+	user, err := c.users.UpdateUserRole(uint(id), body.Role)
 	if err != nil {
-		http.Error(w, "Failed to update user", http.StatusInternalServerError)
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to update user role"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(user)
+	response.OK(w, user)
 }
 
 // DELETE USER
-func DeleteUser(w http.ResponseWriter, r *http.Request) {
+func (c *UserController) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	id, err := strconv.Atoi(params["id"])
 	if err != nil {
-		http.Error(w, "Invalid user ID", http.StatusBadRequest)
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
 		return
 	}
 
-	if err := adminRepo.DeleteUser(uint(id)); err != nil {
-		http.Error(w, "Failed to delete user", http.StatusInternalServerError)
+	if err := c.users.DeleteUser(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to delete user"))
+		return
+	}
+
+	response.Message(w, "User deleted successfully")
+}
+
+// GET TRASHED USERS (đã soft-delete)
+func (c *UserController) GetTrashedUsers(w http.ResponseWriter, r *http.Request) {
+	items, err := c.users.GetTrashedUsers()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch trashed users", err))
 		return
 	}
+	response.OK(w, items)
+}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "User deleted successfully"})
+// RESTORE USER từ thùng rác
+func (c *UserController) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
+		return
+	}
+	if err := c.users.RestoreUser(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found in trash", "Failed to restore user"))
+		return
+	}
+	response.Message(w, "User restored successfully")
 }
+
+// PURGE USER: xóa vĩnh viễn (chỉ bản ghi đã ở thùng rác)
+func (c *UserController) PurgeUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
+		return
+	}
+	if err := c.users.PurgeUser(uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found in trash", "Failed to purge user"))
+		return
+	}
+	response.Message(w, "User permanently deleted")
+}
+
+// UNLOCK USER: ghi LoginLog "unlocked" để reset bộ đếm đăng nhập sai của tài khoản
+func (c *UserController) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
+		return
+	}
+
+	user, err := c.users.GetUserByID(uint(id))
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+
+	message := "Unlocked by admin"
+	if claims := middlewares.GetUserFromContext(r); claims != nil {
+		message = fmt.Sprintf("Unlocked by user #%d", claims.UserID)
+	}
+	if err := c.users.CreateLoginLog(&models.LoginLog{
+		UserID:    user.ID,
+		Role:      user.Role,
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Status:    "unlocked",
+		Message:   message,
+		CreatedAt: time.Now(),
+	}); err != nil {
+		response.Error(w, apperr.Internal("Failed to unlock user", err))
+		return
+	}
+
+	response.Message(w, "User unlocked")
+}
+
+// REVOKE SESSIONS: thu hồi mọi refresh token của user (vd nhân viên nghỉ việc, mất máy)
+func (c *UserController) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+	id, err := strconv.Atoi(params["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid user ID"))
+		return
+	}
+
+	if err := c.users.RevokeAllSessions(uint(id)); err != nil {
+		response.Error(w, apperr.Internal("Failed to revoke sessions", err))
+		return
+	}
+
+	response.Message(w, "User sessions revoked")
+}
+
 // Lấy log đăng nhập của chính user
-func GetUserLogsHandler(w http.ResponseWriter, r *http.Request) {
+func (c *UserController) GetUserLogsHandler(w http.ResponseWriter, r *http.Request) {
 	claims := middlewares.GetUserFromContext(r)
 	if claims == nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	params, ok := listParams(w, r)
+	if !ok {
+		return
+	}
+
+	perms, err := middlewares.GetPermissionsFromContext(r)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to load permissions", err))
 		return
 	}
 
 	var logs []models.LoginLog
-	var err error
+	var meta *listquery.Meta
 
-	if claims.Role == "admin" {
-		// Admin xem được tất cả log
-		err = configs.DB.Order("created_at desc").Find(&logs).Error
+	if perms.Has(service.PermLogsReadAll) {
+		// Role có logs:read_all (admin mặc định) xem được tất cả log
+		logs, meta, err = c.users.GetAllLoginLogs(params)
 	} else {
-		// Staff, customer chỉ xem log của chính mình
-		logs, err = adminRepo.GetLoginLogsByUserID(claims.UserID)
+		// Còn lại chỉ xem log của chính mình
+		logs, meta, err = c.users.GetLoginLogsByUserID(claims.UserID, params)
 	}
 
 	if err != nil {
-		http.Error(w, "Failed to fetch logs", http.StatusInternalServerError)
+		response.Error(w, listError(err, "Failed to fetch logs"))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(logs)
+	response.List(w, logs, meta)
 }
@@ -0,0 +1,23 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/response"
+	"backend/internal/utils"
+	"net/http"
+)
+
+// requireIfMatch lấy version client đang sửa từ header If-Match.
+// Thiếu header -> 428, header sai định dạng -> 412.
+func requireIfMatch(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	version, present, ok := utils.IfMatchVersion(r)
+	if !present {
+		response.Error(w, apperr.PreconditionRequired("If-Match header required"))
+		return 0, false
+	}
+	if !ok {
+		response.Error(w, apperr.PreconditionFailed("Invalid If-Match header"))
+		return 0, false
+	}
+	return version, true
+}
@@ -1,154 +1,469 @@
 package controllers
 
 import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
 	"backend/internal/models"
 	"backend/internal/repository"
+	shopRepo "backend/internal/repository/shop"
+	"backend/internal/response"
 	"backend/internal/service"
 	"backend/internal/utils"
+	"backend/internal/validate"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"time"
-
-	"github.com/golang-jwt/jwt/v5"
 )
 
 // ================= REGISTER =================
 type RegisterRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
-	Email    string `json:"email"`
-	Phone    string `json:"phone"`
-	Address  string `json:"address"`
+	Username string `json:"username" validate:"required,max=255"`
+	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Phone    string `json:"phone" validate:"max=20"`
+	Address  string `json:"address" validate:"max=255"`
 }
 
 func RegisterHandler(w http.ResponseWriter, r *http.Request) {
 	var req RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	hash, err := utils.HashPassword(req.Password)
-	if err != nil {
-		http.Error(w, "Failed to hash password", http.StatusInternalServerError)
+	// Kiểm tra trùng trước khi gửi email
+	if err := repository.CheckUserAvailable(req.Username, req.Email); err != nil {
+		if errors.Is(err, repository.ErrUsernameTaken) || errors.Is(err, repository.ErrEmailTaken) {
+			response.Error(w, err)
+			return
+		}
+		response.Error(w, apperr.Internal("Failed to register", err))
 		return
 	}
 
-	claims := jwt.MapClaims{
-		"username": req.Username,
-		"email":    req.Email,
-		"phone":    req.Phone,
-		"role":     "customer",
-		"hash":     hash,
-		"address":  req.Address,
-		"exp":      time.Now().Add(time.Hour).Unix(),
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to hash password", err))
+		return
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenStr, err := token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+	token, err := repository.SavePendingRegistration(&models.PendingRegistration{
+		Username:     req.Username,
+		Email:        req.Email,
+		Phone:        req.Phone,
+		Address:      req.Address,
+		PasswordHash: hash,
+	})
 	if err != nil {
-		http.Error(w, "Failed to create token", http.StatusInternalServerError)
+		response.Error(w, apperr.Internal("Failed to create token", err))
 		return
 	}
 
-	link := fmt.Sprintf("%s/api/auth/confirm?token=%s",
-		os.Getenv("BACKEND_URL"), url.QueryEscape(tokenStr))
-
-	if err := service.SendConfirmationEmail(req.Email, link); err != nil {
-		http.Error(w, "Failed to send email", http.StatusInternalServerError)
+	if err := service.SendConfirmationEmail(req.Email, confirmLink(token)); err != nil {
+		response.Error(w, apperr.Internal("Failed to send email", err))
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"message": "Please check your email to confirm"})
+	response.Message(w, "Please check your email to confirm")
 }
 
-// ================= CONFIRM REGISTER =================
-func ConfirmRegisterHandler(w http.ResponseWriter, r *http.Request) {
-	tokenStr := r.URL.Query().Get("token")
-	frontend := os.Getenv("FRONTEND_URL")
+func confirmLink(token string) string {
+	return fmt.Sprintf("%s/api/auth/confirm?token=%s",
+		os.Getenv("BACKEND_URL"), url.QueryEscape(token))
+}
 
-	claims := jwt.MapClaims{}
-	token, err := jwt.ParseWithClaims(tokenStr, &claims, func(t *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("JWT_SECRET")), nil
-	})
+// ================= RESEND CONFIRMATION =================
+type ResendConfirmationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
 
-	if err != nil || !token.Valid {
-		http.Redirect(w, r, frontend+"/register/failpage", http.StatusSeeOther)
+// Luôn trả cùng 1 thông báo để không lộ email nào đang chờ xác nhận
+func ResendConfirmationHandler(w http.ResponseWriter, r *http.Request) {
+	var req ResendConfirmationRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
 		return
 	}
 
-	hash, ok := claims["hash"].(string)
-	if !ok || hash == "" {
-		http.Redirect(w, r, frontend+"/register/failpage", http.StatusSeeOther)
+	pending, token, err := repository.RenewPendingRegistration(req.Email)
+	if err != nil && !errors.Is(err, repository.ErrRegistrationNotFound) {
+		response.Error(w, apperr.Internal("Failed to create token", err))
 		return
 	}
-
-	user := models.User{
-		Username:     claims["username"].(string),
-		PasswordHash: hash,
-		Email:        claims["email"].(string),
-		Phone:        claims["phone"].(string),
-		Address:      claims["address"].(string),
-		Role:         "customer",
+	if err == nil {
+		if err := service.SendConfirmationEmail(pending.Email, confirmLink(token)); err != nil {
+			response.Error(w, apperr.Internal("Failed to send email", err))
+			return
+		}
 	}
 
-	if err := repository.CreateUser(&user); err != nil {
-		http.Redirect(w, r, frontend+"/register/failpage", http.StatusSeeOther)
+	response.Message(w, "If a pending registration exists, a new confirmation email has been sent")
+}
+
+// ================= CONFIRM REGISTER =================
+func ConfirmRegisterHandler(w http.ResponseWriter, r *http.Request) {
+	tokenStr := r.URL.Query().Get("token")
+	frontend := os.Getenv("FRONTEND_URL")
+
+	alreadyConfirmed, err := repository.ConfirmPendingRegistration(tokenStr)
+	if err != nil {
+		reason := "invalid"
+		switch {
+		case errors.Is(err, repository.ErrConfirmTokenExpired):
+			reason = "expired"
+		case errors.Is(err, repository.ErrEmailTaken), errors.Is(err, repository.ErrUsernameTaken):
+			reason = "exists"
+		}
+		http.Redirect(w, r, frontend+"/register/failpage?reason="+reason, http.StatusSeeOther)
 		return
 	}
 
+	// Bấm link lần 2: tài khoản đã được tạo, vẫn đưa tới trang hoàn tất
+	if alreadyConfirmed {
+		http.Redirect(w, r, frontend+"/register/complete?already=1", http.StatusSeeOther)
+		return
+	}
 	http.Redirect(w, r, frontend+"/register/complete", http.StatusSeeOther)
 }
 
 // ================= LOGIN =================
 type LoginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+	// Token giỏ hàng khách vãng lai (tuỳ chọn), sẽ được gộp vào giỏ của customer
+	CartToken string `json:"cart_token"`
 }
 
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid input", http.StatusBadRequest)
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	ip := utils.ClientIP(r)
+
+	// 🚫 Chặn IP có quá nhiều lần đăng nhập sai
+	ipFailures, ipLast, err := repository.GetRecentFailedLoginsByIP(ip, service.IPFailureWindow)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to login", err))
+		return
+	}
+	if until := service.LockedUntil(ipLast, service.IPLockoutDuration(ipFailures)); !until.IsZero() {
+		writeLocked(w, apperr.TooManyRequests("Too many failed attempts, try again later"), until)
 		return
 	}
 
 	user, err := repository.GetUserByEmail(req.Email)
-	if err != nil || !utils.CheckPasswordHash(user.PasswordHash, req.Password) {
-		// ❌ Ghi log thất bại
+	if err != nil {
+		// ❌ Ghi log thất bại (email không tồn tại)
 		repository.CreateLoginLog(&models.LoginLog{
 			UserID:    0,
 			Role:      "unknown",
-			IP:        r.RemoteAddr,
+			IP:        ip,
 			UserAgent: r.UserAgent(),
 			Status:    "failed",
-			Message:   "Invalid credentials",
+			Message:   "Unknown email",
 			CreatedAt: time.Now(),
 		})
 
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+		response.Error(w, apperr.Unauthorized("Invalid credentials"))
 		return
 	}
 
-	// ✅ Tạo token
-	token, _ := service.GenerateToken(user.ID, user.Role)
+	// 🔒 Tài khoản đang bị khóa lũy tiến: không kiểm tra mật khẩu
+	failures, lastFailure, err := repository.GetConsecutiveFailedLogins(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to login", err))
+		return
+	}
+	if until := service.LockedUntil(lastFailure, service.AccountLockoutDuration(failures)); !until.IsZero() {
+		repository.CreateLoginLog(&models.LoginLog{
+			UserID:    user.ID,
+			Role:      user.Role,
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			Status:    "locked",
+			Message:   "Login attempt while account locked",
+			CreatedAt: time.Now(),
+		})
+		writeLocked(w, apperr.Locked("Account temporarily locked"), until)
+		return
+	}
+
+	if !utils.CheckPasswordHash(user.PasswordHash, req.Password) {
+		// ❌ Ghi log thất bại, gắn với user thật
+		repository.CreateLoginLog(&models.LoginLog{
+			UserID:    user.ID,
+			Role:      user.Role,
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			Status:    "failed",
+			Message:   "Invalid password",
+			CreatedAt: time.Now(),
+		})
+
+		response.Error(w, apperr.Unauthorized("Invalid credentials"))
+		return
+	}
+
+	cartToken := req.CartToken
+	if cartToken == "" {
+		cartToken = r.Header.Get("X-Cart-Token")
+	}
+
+	// 🔐 Bước 2: bật TOTP (hoặc role bắt buộc 2FA) => chỉ cấp partial token
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to login", err))
+		return
+	}
+	enabled := totp != nil && totp.Enabled
+	if enabled || service.IsMFARequired(user.Role) {
+		mfaToken, err := service.GenerateMFAToken(user.ID, user.Role)
+		if err != nil {
+			response.Error(w, apperr.Internal("Failed to create token", err))
+			return
+		}
+		repository.CreateLoginLog(&models.LoginLog{
+			UserID:    user.ID,
+			Role:      user.Role,
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			Status:    "mfa_required",
+			Message:   "Password verified, waiting for second factor",
+			CreatedAt: time.Now(),
+		})
+
+		response.OK(w, map[string]interface{}{
+			"mfa_required":       true,
+			"mfa_token":          mfaToken,
+			"enrolment_required": !enabled,
+		})
+		return
+	}
+
+	completeLogin(w, r, user, cartToken, nil)
+}
+
+// completeLogin gộp giỏ hàng khách, ghi log thành công và cấp access + refresh token
+func completeLogin(w http.ResponseWriter, r *http.Request, user *models.User, cartToken string, extra map[string]interface{}) {
+	ip := utils.ClientIP(r)
+
+	// Gộp giỏ hàng khách vãng lai (nếu có) vào giỏ của customer
+	if cartToken != "" && user.Role == "customer" {
+		if err := shopRepo.MergeGuestCart(cartToken, user.ID); err != nil {
+			log.Println("Merge guest cart error:", err)
+		}
+	}
 
 	// ✅ Ghi log thành công
 	repository.CreateLoginLog(&models.LoginLog{
 		UserID:    user.ID,
 		Role:      user.Role,
-		IP:        r.RemoteAddr,
+		IP:        ip,
 		UserAgent: r.UserAgent(),
 		Status:    "success",
 		Message:   "Login successful",
 		CreatedAt: time.Now(),
 	})
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"token": token})
+	// ✅ Tạo access token + refresh token
+	refreshToken, _, err := repository.CreateRefreshToken(user.ID, ip, r.UserAgent())
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to create session", err))
+		return
+	}
+	writeTokens(w, user, refreshToken, extra)
+}
+
+// writeLocked trả lỗi khóa đăng nhập kèm Retry-After (giây)
+func writeLocked(w http.ResponseWriter, err *apperr.Error, until time.Time) {
+	retryAfter := int(time.Until(until).Seconds()) + 1
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	response.Error(w, err.WithDetails(map[string]interface{}{"locked_until": until}))
 }
 
+// writeTokens tạo access token (kèm role ids để resolve quyền) và trả cùng refresh token
+func writeTokens(w http.ResponseWriter, user *models.User, refreshToken string, extra map[string]interface{}) {
+	roleIDs, err := repository.GetUserRoleIDs(user)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to load roles", err))
+		return
+	}
+	token, err := service.GenerateToken(user.ID, user.Role, roleIDs)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to create token", err))
+		return
+	}
+
+	resp := map[string]interface{}{
+		"token":         token,
+		"refresh_token": refreshToken,
+		"expires_in":    int(service.AccessTokenTTL.Seconds()),
+	}
+	for k, v := range extra {
+		resp[k] = v
+	}
+
+	response.OK(w, resp)
+}
+
+// ================= REFRESH =================
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func RefreshHandler(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, refreshToken, err := repository.RotateRefreshToken(req.RefreshToken, utils.ClientIP(r), r.UserAgent())
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidRefreshToken) {
+			response.Error(w, apperr.Unauthorized("Invalid refresh token"))
+			return
+		}
+		response.Error(w, apperr.Internal("Failed to refresh token", err))
+		return
+	}
+	writeTokens(w, user, refreshToken, nil)
+}
+
+// ================= LOGOUT =================
+// Thu hồi access token hiện tại (jti) và refresh token gửi kèm (nếu có)
+func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var req RefreshRequest
+	json.NewDecoder(r.Body).Decode(&req)
+
+	if req.RefreshToken != "" {
+		if err := repository.RevokeRefreshToken(claims.UserID, req.RefreshToken); err != nil {
+			response.Error(w, apperr.Internal("Failed to logout", err))
+			return
+		}
+	}
+	if err := repository.RevokeAccessToken(claims); err != nil {
+		response.Error(w, apperr.Internal("Failed to logout", err))
+		return
+	}
+
+	response.Message(w, "Logged out")
+}
+
+// Thu hồi mọi refresh token của user; access token của các phiên khác tự hết hạn sau AccessTokenTTL
+func LogoutAllHandler(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	if err := repository.RevokeAllRefreshTokens(claims.UserID); err != nil {
+		response.Error(w, apperr.Internal("Failed to logout", err))
+		return
+	}
+	if err := repository.RevokeAccessToken(claims); err != nil {
+		response.Error(w, apperr.Internal("Failed to logout", err))
+		return
+	}
+
+	response.Message(w, "Logged out from all sessions")
+}
+
+// ================= FORGOT PASSWORD =================
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// Luôn trả cùng 1 thông báo để không lộ email nào đã đăng ký
+func ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	if user, err := repository.GetUserByEmail(req.Email); err == nil {
+		token, err := repository.CreatePasswordResetToken(user.ID)
+		if err != nil {
+			response.Error(w, apperr.Internal("Failed to create reset token", err))
+			return
+		}
+
+		link := fmt.Sprintf("%s/reset-password?token=%s",
+			os.Getenv("FRONTEND_URL"), url.QueryEscape(token))
+		if err := service.SendPasswordResetEmail(user.Email, link); err != nil {
+			response.Error(w, apperr.Internal("Failed to send email", err))
+			return
+		}
+
+		repository.CreateLoginLog(&models.LoginLog{
+			UserID:    user.ID,
+			Role:      user.Role,
+			IP:        utils.ClientIP(r),
+			UserAgent: r.UserAgent(),
+			Status:    "reset_requested",
+			Message:   "Password reset requested",
+			CreatedAt: time.Now(),
+		})
+	}
+
+	response.Message(w, "If the email exists, a reset link has been sent")
+}
+
+// ================= RESET PASSWORD =================
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+func ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to hash password", err))
+		return
+	}
+
+	user, err := repository.ResetPassword(req.Token, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidResetToken) {
+			response.Error(w, apperr.BadRequest("Invalid or expired reset token"))
+			return
+		}
+		response.Error(w, apperr.Internal("Failed to reset password", err))
+		return
+	}
+
+	repository.CreateLoginLog(&models.LoginLog{
+		UserID:    user.ID,
+		Role:      user.Role,
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Status:    "password_reset",
+		Message:   "Password reset via email link",
+		CreatedAt: time.Now(),
+	})
+
+	response.Message(w, "Password has been reset")
+}
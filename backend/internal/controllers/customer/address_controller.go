@@ -0,0 +1,172 @@
+package customer
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	customerRepo "backend/internal/repository/customer"
+	"backend/internal/response"
+	"backend/internal/validate"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+type addressInput struct {
+	RecipientName string `json:"recipient_name" validate:"required,max=255"`
+	Phone         string `json:"phone" validate:"required,max=20"`
+	Province      string `json:"province" validate:"required,max=100"`
+	District      string `json:"district" validate:"required,max=100"`
+	Ward          string `json:"ward" validate:"required,max=100"`
+	Street        string `json:"street" validate:"required,max=255"`
+	IsDefault     *bool  `json:"is_default"`
+}
+
+// toShippingAddress chuẩn hóa (trim) các field của địa chỉ
+func (in addressInput) toShippingAddress() models.ShippingAddress {
+	return models.ShippingAddress{
+		RecipientName: strings.TrimSpace(in.RecipientName),
+		Phone:         strings.TrimSpace(in.Phone),
+		Province:      strings.TrimSpace(in.Province),
+		District:      strings.TrimSpace(in.District),
+		Ward:          strings.TrimSpace(in.Ward),
+		Street:        strings.TrimSpace(in.Street),
+	}
+}
+
+// GET /api/me/addresses
+func GetMyAddresses(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	addresses, err := customerRepo.GetMyAddresses(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch addresses", err))
+		return
+	}
+
+	response.OK(w, addresses)
+}
+
+// POST /api/me/addresses
+func CreateMyAddress(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var body addressInput
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+	data := body.toShippingAddress()
+
+	address := models.UserAddress{Address: data, IsDefault: body.IsDefault != nil && *body.IsDefault}
+	if err := customerRepo.CreateMyAddress(claims.UserID, &address); err != nil {
+		response.Error(w, apperr.Internal("Failed to create address", err))
+		return
+	}
+
+	response.Created(w, address)
+}
+
+// GET /api/me/addresses/{id}
+func GetMyAddress(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid address ID"))
+		return
+	}
+
+	address, err := customerRepo.GetMyAddress(claims.UserID, uint(id))
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Address not found", "Failed to fetch address"))
+		return
+	}
+
+	response.OK(w, address)
+}
+
+// PUT /api/me/addresses/{id}
+func UpdateMyAddress(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid address ID"))
+		return
+	}
+
+	var body addressInput
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+	data := body.toShippingAddress()
+
+	address, err := customerRepo.UpdateMyAddress(claims.UserID, uint(id), data, body.IsDefault)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Address not found", "Failed to update address"))
+		return
+	}
+
+	response.OK(w, address)
+}
+
+// POST /api/me/addresses/{id}/default
+func SetDefaultAddress(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid address ID"))
+		return
+	}
+
+	address, err := customerRepo.SetDefaultAddress(claims.UserID, uint(id))
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Address not found", "Failed to set default address"))
+		return
+	}
+
+	response.OK(w, address)
+}
+
+// DELETE /api/me/addresses/{id}
+func DeleteMyAddress(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid address ID"))
+		return
+	}
+
+	if err := customerRepo.DeleteMyAddress(claims.UserID, uint(id)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Address not found", "Failed to delete address"))
+		return
+	}
+
+	response.Message(w, "Address deleted")
+}
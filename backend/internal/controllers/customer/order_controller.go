@@ -0,0 +1,88 @@
+package customer
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
+	customerRepo "backend/internal/repository/customer"
+	"backend/internal/response"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// OrderController xử lý các API order của customer đang đăng nhập
+type OrderController struct {
+	orders customerRepo.OrderRepository
+}
+
+func NewOrderController(orders customerRepo.OrderRepository) *OrderController {
+	return &OrderController{orders: orders}
+}
+
+// GET /api/me/orders
+func (c *OrderController) GetMyOrders(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	orders, err := c.orders.GetMyOrders(claims.UserID, r.URL.Query().Get("status"))
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch orders", err))
+		return
+	}
+
+	response.OK(w, orders)
+}
+
+// GET /api/me/orders/{id}
+func (c *OrderController) GetMyOrderDetail(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid order ID"))
+		return
+	}
+
+	order, err := c.orders.GetMyOrderDetail(claims.UserID, uint(id))
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Order not found", "Failed to fetch order"))
+		return
+	}
+
+	response.OK(w, order)
+}
+
+// POST /api/me/orders/{id}/cancel
+func (c *OrderController) CancelMyOrder(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid order ID"))
+		return
+	}
+
+	// Lý do hủy là tùy chọn
+	var body struct {
+		Note string `json:"note"`
+	}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	if err := c.orders.CancelMyOrder(claims, uint(id), body.Note); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Order not found", "Failed to cancel order"))
+		return
+	}
+
+	response.Message(w, "Order cancelled")
+}
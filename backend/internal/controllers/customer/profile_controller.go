@@ -0,0 +1,170 @@
+package customer
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/repository"
+	customerRepo "backend/internal/repository/customer"
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/internal/utils"
+	"backend/internal/validate"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// GET /api/me
+func GetProfile(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	user, err := customerRepo.GetProfile(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+	response.OK(w, user)
+}
+
+// PATCH /api/me
+// Chỉ sửa được username, phone, address; email đổi qua POST /api/me/email, role không sửa được
+func UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var body struct {
+		Username *string `json:"username" validate:"notblank,max=255"`
+		Phone    *string `json:"phone" validate:"max=20"`
+		Address  *string `json:"address" validate:"max=255"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := customerRepo.UpdateProfile(claims.UserID, customerRepo.ProfileUpdate{
+		Username: body.Username,
+		Phone:    body.Phone,
+		Address:  body.Address,
+	})
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to update profile"))
+		return
+	}
+
+	response.OK(w, user)
+}
+
+// POST /api/me/password
+func ChangePassword(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var body struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		NewPassword     string `json:"new_password" validate:"required"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := customerRepo.GetProfile(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+	if !utils.CheckPasswordHash(user.PasswordHash, body.CurrentPassword) {
+		response.Error(w, apperr.Unauthorized("Current password is incorrect"))
+		return
+	}
+
+	hash, err := utils.HashPassword(body.NewPassword)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to hash password", err))
+		return
+	}
+	if err := customerRepo.ChangePassword(user.ID, hash); err != nil {
+		response.Error(w, apperr.Internal("Failed to change password", err))
+		return
+	}
+
+	repository.CreateLoginLog(&models.LoginLog{
+		UserID:    user.ID,
+		Role:      user.Role,
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Status:    "password_changed",
+		Message:   "Password changed by user",
+		CreatedAt: time.Now(),
+	})
+
+	response.Message(w, "Password changed")
+}
+
+// POST /api/me/email
+// Gửi link xác nhận tới email mới; email chỉ được đổi sau khi bấm link
+func RequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+
+	var body struct {
+		Email    string `json:"email" validate:"required,email,max=255"`
+		Password string `json:"password" validate:"required"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := customerRepo.GetProfile(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+	if !utils.CheckPasswordHash(user.PasswordHash, body.Password) {
+		response.Error(w, apperr.Unauthorized("Password is incorrect"))
+		return
+	}
+
+	token, err := customerRepo.CreateEmailChangeRequest(user.ID, body.Email)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to request email change"))
+		return
+	}
+
+	link := fmt.Sprintf("%s/api/me/email/confirm?token=%s",
+		os.Getenv("BACKEND_URL"), url.QueryEscape(token))
+	if err := service.SendEmailChangeEmail(body.Email, link); err != nil {
+		response.Error(w, apperr.Internal("Failed to send email", err))
+		return
+	}
+
+	response.Message(w, "Please check your new email to confirm")
+}
+
+// GET /api/me/email/confirm?token=...
+// Link trong email, không cần JWT; redirect về FE giống luồng xác nhận đăng ký
+func ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	frontend := os.Getenv("FRONTEND_URL")
+	if err := customerRepo.ConfirmEmailChange(r.URL.Query().Get("token")); err != nil {
+		http.Redirect(w, r, frontend+"/profile/email?status=failed", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, frontend+"/profile/email?status=confirmed", http.StatusSeeOther)
+}
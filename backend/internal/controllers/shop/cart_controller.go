@@ -0,0 +1,164 @@
+package shop
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	shopRepo "backend/internal/repository/shop"
+	"backend/internal/response"
+	"backend/internal/validate"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// CartTokenHeader: header chứa token giỏ hàng của khách vãng lai
+const CartTokenHeader = "X-Cart-Token"
+
+// resolveCart tìm giỏ hàng của request hiện tại.
+// Customer đã đăng nhập dùng giỏ theo UserID; khách vãng lai dùng X-Cart-Token.
+// Khi create = true và khách chưa có giỏ thì tạo mới và trả token qua header.
+func resolveCart(w http.ResponseWriter, r *http.Request, create bool) (*models.Cart, bool) {
+	if claims := middlewares.GetUserFromContext(r); claims != nil {
+		if claims.Role != "customer" {
+			response.Error(w, apperr.Forbidden("Only customers can use the cart"))
+			return nil, false
+		}
+		cart, err := shopRepo.GetOrCreateUserCart(claims.UserID)
+		if err != nil {
+			response.Error(w, apperr.Internal("Failed to load cart", err))
+			return nil, false
+		}
+		return cart, true
+	}
+
+	if token := r.Header.Get(CartTokenHeader); token != "" {
+		if cart, err := shopRepo.GetGuestCart(token); err == nil {
+			return cart, true
+		}
+	}
+	if !create {
+		return nil, true
+	}
+
+	cart, token, err := shopRepo.CreateGuestCart()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to create cart", err))
+		return nil, false
+	}
+	w.Header().Set(CartTokenHeader, token)
+	return cart, true
+}
+
+func writeCart(w http.ResponseWriter, cartID uint) {
+	cart, err := shopRepo.LoadCart(cartID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to load cart", err))
+		return
+	}
+	response.OK(w, cart)
+}
+
+// GET /api/shop/cart
+func GetCart(w http.ResponseWriter, r *http.Request) {
+	cart, ok := resolveCart(w, r, false)
+	if !ok {
+		return
+	}
+	if cart == nil {
+		response.OK(w, models.Cart{Items: []models.CartItem{}})
+		return
+	}
+	writeCart(w, cart.ID)
+}
+
+// POST /api/shop/cart/items
+func AddCartItem(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		VariantID uint `json:"variant_id" validate:"required"`
+		Quantity  int  `json:"quantity" validate:"min=1"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	cart, ok := resolveCart(w, r, true)
+	if !ok {
+		return
+	}
+	if err := shopRepo.AddCartItem(cart.ID, body.VariantID, body.Quantity); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Cart item not found", "Failed to update cart"))
+		return
+	}
+	writeCart(w, cart.ID)
+}
+
+// PUT /api/shop/cart/items/{itemId}
+func UpdateCartItem(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.Atoi(mux.Vars(r)["itemId"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid item ID"))
+		return
+	}
+	var body struct {
+		Quantity int `json:"quantity" validate:"min=1"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	cart, ok := resolveCart(w, r, false)
+	if !ok {
+		return
+	}
+	if cart == nil {
+		response.Error(w, apperr.NotFound("Cart item not found"))
+		return
+	}
+	if err := shopRepo.UpdateCartItem(cart.ID, uint(itemID), body.Quantity); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Cart item not found", "Failed to update cart"))
+		return
+	}
+	writeCart(w, cart.ID)
+}
+
+// DELETE /api/shop/cart/items/{itemId}
+func RemoveCartItem(w http.ResponseWriter, r *http.Request) {
+	itemID, err := strconv.Atoi(mux.Vars(r)["itemId"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid item ID"))
+		return
+	}
+
+	cart, ok := resolveCart(w, r, false)
+	if !ok {
+		return
+	}
+	if cart == nil {
+		response.Error(w, apperr.NotFound("Cart item not found"))
+		return
+	}
+	if err := shopRepo.RemoveCartItem(cart.ID, uint(itemID)); err != nil {
+		response.Error(w, apperr.FromRepo(err, "Cart item not found", "Failed to update cart"))
+		return
+	}
+	writeCart(w, cart.ID)
+}
+
+// DELETE /api/shop/cart
+func ClearCart(w http.ResponseWriter, r *http.Request) {
+	cart, ok := resolveCart(w, r, false)
+	if !ok {
+		return
+	}
+	if cart != nil {
+		if err := shopRepo.ClearCart(cart.ID); err != nil {
+			response.Error(w, apperr.FromRepo(err, "Cart item not found", "Failed to update cart"))
+			return
+		}
+	}
+	response.Message(w, "Cart cleared")
+}
@@ -0,0 +1,112 @@
+package shop
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/listquery"
+	shopRepo "backend/internal/repository/shop"
+	"backend/internal/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultPerPage = 12
+	maxPerPage     = 100
+)
+
+// GET /api/shop/products
+// Query: page, per_page, sort (newest|price_asc|price_desc), category_id, group, size, color, min_price, max_price
+func GetCatalogProducts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := shopRepo.CatalogFilter{
+		Group: q.Get("group"),
+		Size:  q.Get("size"),
+		Color: q.Get("color"),
+		Sort:  q.Get("sort"),
+	}
+
+	filter.PerPage, _ = strconv.Atoi(q.Get("per_page"))
+	if filter.PerPage < 1 {
+		filter.PerPage = defaultPerPage
+	}
+	if filter.PerPage > maxPerPage {
+		filter.PerPage = maxPerPage
+	}
+	filter.Page = 1
+	if v := q.Get("page"); v != "" {
+		// Cùng giới hạn với listquery để offset (page-1)*per_page không tràn số
+		page, err := strconv.Atoi(v)
+		if err != nil || page > listquery.MaxOffset/filter.PerPage+1 {
+			response.Error(w, apperr.BadRequest("Invalid page"))
+			return
+		}
+		if page > 1 {
+			filter.Page = page
+		}
+	}
+
+	if v := q.Get("category_id"); v != "" {
+		id, err := strconv.Atoi(v)
+		if err != nil || id < 1 {
+			response.Error(w, apperr.BadRequest("Invalid category_id"))
+			return
+		}
+		filter.CategoryID = uint(id)
+	}
+	if v := q.Get("min_price"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			response.Error(w, apperr.BadRequest("Invalid min_price"))
+			return
+		}
+		filter.MinPrice = price
+	}
+	if v := q.Get("max_price"); v != "" {
+		price, err := strconv.ParseFloat(v, 64)
+		if err != nil || price < 0 {
+			response.Error(w, apperr.BadRequest("Invalid max_price"))
+			return
+		}
+		filter.MaxPrice = price
+	}
+
+	products, total, err := shopRepo.GetCatalogProducts(filter)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch products", err))
+		return
+	}
+
+	response.List(w, products, map[string]interface{}{
+		"total":    total,
+		"page":     filter.Page,
+		"per_page": filter.PerPage,
+	})
+}
+
+// GET /api/shop/products/{id}
+func GetCatalogProductDetail(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, apperr.BadRequest("Invalid product ID"))
+		return
+	}
+	product, err := shopRepo.GetCatalogProductDetail(uint(id))
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Product not found", "Failed to fetch product"))
+		return
+	}
+	response.OK(w, product)
+}
+
+// GET /api/shop/categories
+func GetCatalogCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := shopRepo.GetCatalogCategories()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to fetch categories", err))
+		return
+	}
+	response.OK(w, categories)
+}
@@ -0,0 +1,38 @@
+package shop
+
+import (
+	"backend/internal/listquery"
+	"backend/internal/testdb"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// page bị giới hạn như listquery để offset không tràn số
+func TestGetCatalogProductsPageBounds(t *testing.T) {
+	testdb.Open(t)
+	maxPage := strconv.Itoa(listquery.MaxOffset/maxPerPage + 1)
+	tests := []struct {
+		query  string
+		status int
+	}{
+		{"", http.StatusOK},
+		{"page=0", http.StatusOK},
+		{"page=2&per_page=100", http.StatusOK},
+		{"page=" + maxPage + "&per_page=100", http.StatusOK},
+		{"page=" + maxPage + "1&per_page=100", http.StatusBadRequest},
+		{"page=9223372036854775807", http.StatusBadRequest},
+		{"page=99999999999999999999", http.StatusBadRequest},
+		{"page=abc", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			GetCatalogProducts(w, httptest.NewRequest("GET", "/api/shop/products?"+tt.query, nil))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
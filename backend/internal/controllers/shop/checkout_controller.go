@@ -0,0 +1,66 @@
+package shop
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
+	customerRepo "backend/internal/repository/customer"
+	shopRepo "backend/internal/repository/shop"
+	"backend/internal/response"
+	"backend/internal/validate"
+	"net/http"
+)
+
+// POST /api/shop/checkout
+// Body: {"payment_method": "cod|online", "address_id": 3, "items": [{"variant_id": 1, "quantity": 2}]}
+// Nếu không gửi items thì lấy từ giỏ hàng của customer và làm trống giỏ sau khi đặt.
+// Nếu không gửi address_id thì giao tới địa chỉ mặc định trong sổ địa chỉ.
+func Checkout(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if claims == nil {
+		response.Error(w, apperr.Unauthorized("Unauthorized"))
+		return
+	}
+	if claims.Role != "customer" {
+		response.Error(w, apperr.Forbidden("Only customers can place orders"))
+		return
+	}
+
+	var body struct {
+		PaymentMethod string                  `json:"payment_method" validate:"omitempty,enum=payment_method"`
+		AddressID     uint                    `json:"address_id"`
+		Items         []shopRepo.CheckoutItem `json:"items"`
+	}
+	if err := validate.DecodeJSON(r, &body); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	if body.PaymentMethod == "" {
+		body.PaymentMethod = "cod"
+	}
+
+	address, err := customerRepo.GetShippingAddress(claims.UserID, body.AddressID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Address not found", "Failed to load address"))
+		return
+	}
+
+	items := body.Items
+	fromCart := len(items) == 0
+	if fromCart {
+		var err error
+		items, err = shopRepo.GetCartCheckoutItems(claims.UserID)
+		if err != nil {
+			response.Error(w, apperr.Internal("Failed to load cart", err))
+			return
+		}
+	}
+
+	order, err := shopRepo.PlaceOrder(claims.UserID, body.PaymentMethod, address.Address, items, fromCart)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "Variant not found", "Failed to place order"))
+		return
+	}
+
+	response.Created(w, order)
+}
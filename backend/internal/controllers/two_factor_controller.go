@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/middlewares"
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/response"
+	"backend/internal/service"
+	"backend/internal/utils"
+	"backend/internal/validate"
+	"net/http"
+	"time"
+)
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+	// Token giỏ hàng khách vãng lai, gộp khi hoàn tất đăng nhập
+	CartToken string `json:"cart_token"`
+}
+
+// verifySecondFactor chấp nhận mã TOTP (chống dùng lại theo time step) hoặc 1 backup code
+func verifySecondFactor(totp *models.UserTOTP, code string) (bool, error) {
+	if step, ok := service.ValidateTOTP(totp.Secret, code, time.Now()); ok {
+		return repository.UseTOTPStep(totp.UserID, step)
+	}
+	return repository.UseBackupCode(totp.UserID, code)
+}
+
+func logSecondFactorFailure(r *http.Request, user *models.User) {
+	repository.CreateLoginLog(&models.LoginLog{
+		UserID:    user.ID,
+		Role:      user.Role,
+		IP:        utils.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		Status:    "failed",
+		Message:   "Invalid 2FA code",
+		CreatedAt: time.Now(),
+	})
+}
+
+// checkSecondFactorLock: mã 2FA sai cũng tính vào khóa lũy tiến của tài khoản, nên mọi
+// endpoint nhận mã bằng partial token phải kiểm tra khóa trước khi thử mã.
+// Trả false (đã ghi response) nếu tài khoản đang bị khóa hoặc lỗi DB.
+func checkSecondFactorLock(w http.ResponseWriter, user *models.User, failMessage string) bool {
+	failures, lastFailure, err := repository.GetConsecutiveFailedLogins(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal(failMessage, err))
+		return false
+	}
+	if until := service.LockedUntil(lastFailure, service.AccountLockoutDuration(failures)); !until.IsZero() {
+		writeLocked(w, apperr.Locked("Account temporarily locked"), until)
+		return false
+	}
+	return true
+}
+
+// ================= 2FA SETUP =================
+// Tạo secret mới và trả otpauth URI để FE render QR; chỉ có hiệu lực sau khi enable
+func TwoFactorSetupHandler(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	user, err := repository.GetUserByID(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to setup 2FA", err))
+		return
+	}
+	if totp != nil && totp.Enabled {
+		response.Error(w, apperr.Conflict("2FA already enabled"))
+		return
+	}
+
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to setup 2FA", err))
+		return
+	}
+	if err := repository.SaveTOTPSecret(user.ID, secret); err != nil {
+		response.Error(w, apperr.Internal("Failed to setup 2FA", err))
+		return
+	}
+
+	response.OK(w, map[string]string{
+		"secret":      secret,
+		"otpauth_uri": service.TOTPProvisioningURI(secret, user.Email),
+	})
+}
+
+// ================= 2FA ENABLE =================
+// Xác nhận mã đầu tiên, bật TOTP và trả backup codes (chỉ hiển thị 1 lần).
+// Gọi bằng partial token (admin enrol bắt buộc khi đăng nhập) thì hoàn tất đăng nhập luôn.
+func TwoFactorEnableHandler(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	var req TwoFactorCodeRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := repository.GetUserByID(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to enable 2FA", err))
+		return
+	}
+	if totp == nil {
+		response.Error(w, apperr.BadRequest("Run 2FA setup first"))
+		return
+	}
+	if totp.Enabled {
+		response.Error(w, apperr.Conflict("2FA already enabled"))
+		return
+	}
+	if !checkSecondFactorLock(w, user, "Failed to enable 2FA") {
+		return
+	}
+
+	step, ok := service.ValidateTOTP(totp.Secret, req.Code, time.Now())
+	if !ok {
+		logSecondFactorFailure(r, user)
+		response.Error(w, apperr.Unauthorized("Invalid code"))
+		return
+	}
+
+	codes, err := service.GenerateBackupCodes()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to enable 2FA", err))
+		return
+	}
+	if err := repository.EnableTOTP(user.ID, step, codes); err != nil {
+		response.Error(w, apperr.Internal("Failed to enable 2FA", err))
+		return
+	}
+
+	if claims.Stage == service.StageMFA {
+		// Partial token chỉ dùng 1 lần; thu hồi lỗi thì không hoàn tất đăng nhập
+		if err := repository.RevokeAccessToken(claims); err != nil {
+			response.Error(w, apperr.Internal("Failed to complete login", err))
+			return
+		}
+		completeLogin(w, r, user, req.CartToken, map[string]interface{}{"backup_codes": codes})
+		return
+	}
+
+	response.OK(w, map[string]interface{}{
+		"message":      "2FA enabled",
+		"backup_codes": codes,
+	})
+}
+
+// ================= 2FA VERIFY (bước 2 đăng nhập) =================
+func TwoFactorVerifyHandler(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	var req TwoFactorCodeRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := repository.GetUserByID(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+
+	if !checkSecondFactorLock(w, user, "Failed to verify code") {
+		return
+	}
+
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to verify code", err))
+		return
+	}
+	if totp == nil || !totp.Enabled {
+		response.Error(w, apperr.Forbidden("2FA enrolment required"))
+		return
+	}
+
+	ok, err := verifySecondFactor(totp, req.Code)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to verify code", err))
+		return
+	}
+	if !ok {
+		logSecondFactorFailure(r, user)
+		response.Error(w, apperr.Unauthorized("Invalid code"))
+		return
+	}
+
+	// Partial token chỉ dùng 1 lần; thu hồi lỗi thì không hoàn tất đăng nhập
+	if err := repository.RevokeAccessToken(claims); err != nil {
+		response.Error(w, apperr.Internal("Failed to complete login", err))
+		return
+	}
+	completeLogin(w, r, user, req.CartToken, nil)
+}
+
+// ================= 2FA DISABLE =================
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+func TwoFactorDisableHandler(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	if service.IsMFARequired(claims.Role) {
+		response.Error(w, apperr.Forbidden("2FA is mandatory for this role"))
+		return
+	}
+
+	var req TwoFactorDisableRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := repository.GetUserByID(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to disable 2FA", err))
+		return
+	}
+	if totp == nil || !totp.Enabled {
+		response.Error(w, apperr.BadRequest("2FA is not enabled"))
+		return
+	}
+
+	if !utils.CheckPasswordHash(user.PasswordHash, req.Password) {
+		response.Error(w, apperr.Unauthorized("Invalid credentials"))
+		return
+	}
+	if ok, err := verifySecondFactor(totp, req.Code); err != nil || !ok {
+		logSecondFactorFailure(r, user)
+		response.Error(w, apperr.Unauthorized("Invalid code"))
+		return
+	}
+
+	if err := repository.DisableTOTP(user.ID); err != nil {
+		response.Error(w, apperr.Internal("Failed to disable 2FA", err))
+		return
+	}
+
+	response.Message(w, "2FA disabled")
+}
+
+// ================= 2FA BACKUP CODES =================
+// Tạo lại bộ backup code (các mã cũ mất hiệu lực)
+func TwoFactorBackupCodesHandler(w http.ResponseWriter, r *http.Request) {
+	claims := middlewares.GetUserFromContext(r)
+	var req TwoFactorCodeRequest
+	if err := validate.DecodeJSON(r, &req); err != nil {
+		response.Error(w, err)
+		return
+	}
+
+	user, err := repository.GetUserByID(claims.UserID)
+	if err != nil {
+		response.Error(w, apperr.FromRepo(err, "User not found", "Failed to fetch user"))
+		return
+	}
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to regenerate backup codes", err))
+		return
+	}
+	if totp == nil || !totp.Enabled {
+		response.Error(w, apperr.BadRequest("2FA is not enabled"))
+		return
+	}
+
+	step, ok := service.ValidateTOTP(totp.Secret, req.Code, time.Now())
+	if ok {
+		ok, err = repository.UseTOTPStep(user.ID, step)
+	}
+	if err != nil || !ok {
+		logSecondFactorFailure(r, user)
+		response.Error(w, apperr.Unauthorized("Invalid code"))
+		return
+	}
+
+	codes, err := service.GenerateBackupCodes()
+	if err != nil {
+		response.Error(w, apperr.Internal("Failed to regenerate backup codes", err))
+		return
+	}
+	if err := repository.ReplaceBackupCodes(user.ID, codes); err != nil {
+		response.Error(w, apperr.Internal("Failed to regenerate backup codes", err))
+		return
+	}
+
+	response.OK(w, map[string]interface{}{"backup_codes": codes})
+}
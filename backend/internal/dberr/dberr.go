@@ -0,0 +1,214 @@
+// Package dberr chuyển lỗi vi phạm ràng buộc của DB sang lỗi có kiểu của apperr:
+// unique -> 409 Conflict, foreign key -> 422 Validation, kèm tên field bị lỗi.
+//
+// Mỗi dialect báo lỗi khác nhau (MySQL: số lỗi + message, PostgreSQL: SQLSTATE + Detail,
+// SQLite: mã lỗi mở rộng + message) nên việc nhận diện nằm ở đây, repository không phải biết.
+// Register gắn callback vào *gorm.DB để mọi lệnh create / update đều được chuyển đổi.
+package dberr
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/validate"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/glebarez/go-sqlite"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+var (
+	ErrDuplicate = apperr.Conflict("Duplicate value").WithCode("duplicate_value")
+	ErrReference = validate.ErrValidation
+)
+
+type constraint int
+
+const (
+	unique constraint = iota + 1
+	foreignKey
+)
+
+// violation: ràng buộc bị vi phạm; column hoặc index rỗng nếu dialect không cho biết
+type violation struct {
+	kind   constraint
+	column string
+	index  string
+}
+
+var (
+	mysqlFKColumn   = regexp.MustCompile("FOREIGN KEY \\(`([^`]+)`\\)")
+	mysqlUniqueKey  = regexp.MustCompile(`for key '(?:[^'.]+\.)?([^']+)'`)
+	pgKeyColumn     = regexp.MustCompile(`Key \(([^,)]+)`)
+	sqliteUniqueCol = regexp.MustCompile(`UNIQUE constraint failed: [^.\s]+\.([^,\s]+)`)
+)
+
+// SQLite extended result codes
+const (
+	sqliteConstraintForeignKey = 787
+	sqliteConstraintPrimaryKey = 1555
+	sqliteConstraintUnique     = 2067
+)
+
+// parse nhận diện lỗi ràng buộc theo kiểu lỗi của từng driver
+func parse(err error) (violation, bool) {
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		switch myErr.Number {
+		case 1062:
+			v := violation{kind: unique}
+			if m := mysqlUniqueKey.FindStringSubmatch(myErr.Message); m != nil {
+				v.index = m[1]
+			}
+			return v, true
+		case 1452:
+			v := violation{kind: foreignKey}
+			if m := mysqlFKColumn.FindStringSubmatch(myErr.Message); m != nil {
+				v.column = m[1]
+			}
+			return v, true
+		}
+		return violation{}, false
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		v := violation{index: pgErr.ConstraintName}
+		switch pgErr.Code {
+		case "23505":
+			v.kind = unique
+		case "23503":
+			v.kind = foreignKey
+		default:
+			return violation{}, false
+		}
+		if m := pgKeyColumn.FindStringSubmatch(pgErr.Detail); m != nil {
+			v.column = strings.Trim(m[1], `"`)
+		}
+		return v, true
+	}
+
+	var liteErr *sqlite.Error
+	if errors.As(err, &liteErr) {
+		switch liteErr.Code() {
+		case sqliteConstraintUnique, sqliteConstraintPrimaryKey:
+			v := violation{kind: unique}
+			if m := sqliteUniqueCol.FindStringSubmatch(liteErr.Error()); m != nil {
+				v.column = m[1]
+			}
+			return v, true
+		case sqliteConstraintForeignKey:
+			// SQLite không cho biết cột nào, callback tự dò theo quan hệ belongs-to
+			return violation{kind: foreignKey}, true
+		}
+	}
+	return violation{}, false
+}
+
+// error tạo lỗi apperr cho vi phạm, field rỗng thì không kèm details
+func (v violation) error(field string, cause error) error {
+	if v.kind == unique {
+		e := ErrDuplicate
+		if field != "" {
+			e = e.WithDetails([]validate.FieldError{{Field: field, Rule: "unique", Message: "is already taken"}})
+		}
+		return e.Wrap(cause)
+	}
+	e := ErrReference
+	if field != "" {
+		e = e.WithDetails([]validate.FieldError{{Field: field, Rule: "exists", Message: "references a record that does not exist"}})
+	}
+	return e.Wrap(cause)
+}
+
+// Register gắn callback chuyển đổi lỗi sau create / update của db
+func Register(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("dberr:translate", translateCallback); err != nil {
+		return err
+	}
+	return db.Callback().Update().After("gorm:update").Register("dberr:translate", translateCallback)
+}
+
+func translateCallback(db *gorm.DB) {
+	if db.Error == nil {
+		return
+	}
+	v, ok := parse(db.Error)
+	if !ok {
+		return
+	}
+	db.Error = v.error(fieldName(db, v), db.Error)
+}
+
+// fieldName đổi cột / index bị lỗi sang tên field json của model đang ghi
+func fieldName(db *gorm.DB, v violation) string {
+	sch := db.Statement.Schema
+	if sch == nil {
+		return v.column
+	}
+	column := v.column
+	if column == "" && v.index != "" {
+		if idx := sch.LookIndex(v.index); idx != nil && len(idx.Fields) > 0 {
+			column = idx.Fields[0].DBName
+		}
+	}
+	if column == "" && v.kind == foreignKey {
+		column = missingReference(db)
+	}
+	if field := sch.LookUpField(column); field != nil {
+		return jsonName(field)
+	}
+	return column
+}
+
+// missingReference tìm khóa ngoại (belongs-to) đang trỏ tới bản ghi không tồn tại
+func missingReference(db *gorm.DB) string {
+	sch := db.Statement.Schema
+	// Session mới vẫn mang lỗi của lệnh vừa hỏng, phải xóa để truy vấn dò chạy được
+	lookup := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	lookup.Error = nil
+	for _, rel := range sch.Relationships.BelongsTo {
+		if len(rel.References) != 1 {
+			continue
+		}
+		ref := rel.References[0]
+		value, zero := referenceValue(db, ref.ForeignKey)
+		if zero {
+			continue
+		}
+		var count int64
+		err := lookup.Table(rel.FieldSchema.Table).Where(ref.PrimaryKey.DBName+" = ?", value).Count(&count).Error
+		if err == nil && count == 0 {
+			return ref.ForeignKey.DBName
+		}
+	}
+	return ""
+}
+
+// referenceValue lấy giá trị khóa ngoại đang được ghi (Updates bằng map hoặc struct)
+func referenceValue(db *gorm.DB, field *schema.Field) (interface{}, bool) {
+	if values, ok := db.Statement.Dest.(map[string]interface{}); ok {
+		for _, key := range []string{field.DBName, field.Name} {
+			if value, ok := values[key]; ok {
+				return value, value == nil
+			}
+		}
+		return nil, true
+	}
+	rv := db.Statement.ReflectValue
+	if rv.Kind() != reflect.Struct {
+		return nil, true
+	}
+	return field.ValueOf(db.Statement.Context, rv)
+}
+
+func jsonName(field *schema.Field) string {
+	if name, _, _ := strings.Cut(field.Tag.Get("json"), ","); name != "" && name != "-" {
+		return name
+	}
+	return field.DBName
+}
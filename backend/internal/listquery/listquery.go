@@ -0,0 +1,345 @@
+// Package listquery phân trang, sắp xếp và lọc cho các API danh sách.
+//
+// Query string dùng chung:
+//
+//	page, per_page      phân trang theo offset (per_page mặc định 20, tối đa 100)
+//	cursor              phân trang theo keyset: giá trị next_cursor của trang trước (bỏ qua page)
+//	sort                cột sắp xếp, thêm "-" phía trước để giảm dần (vd sort=-created_at)
+//	<filter>=a,b        lọc bằng (nhiều giá trị = IN)
+//	<filter>_from / _to lọc khoảng với cột thời gian (YYYY-MM-DD hoặc RFC3339, _to tính hết ngày)
+//
+// Mỗi resource khai báo Spec whitelist cột được sort / filter; tham số không có trong Spec bị bỏ qua,
+// sort ngoài whitelist hoặc giá trị sai kiểu trả lỗi 422 theo field.
+package listquery
+
+import (
+	"backend/internal/validate"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	DefaultPerPage = 20
+	MaxPerPage     = 100
+	// MaxOffset: offset lớn nhất của phân trang theo page (duyệt sâu hơn thì dùng cursor)
+	MaxOffset = math.MaxInt32
+)
+
+// Spec: whitelist của 1 resource
+type Spec struct {
+	// Sorts: tên dùng trong ?sort= -> cột DB
+	Sorts map[string]string
+	// DefaultSort: sort khi client không gửi, vd "-created_at"
+	DefaultSort string
+	// Filters: tên tham số -> cột DB; cột thời gian nhận <tên>_from / <tên>_to
+	Filters map[string]string
+}
+
+// Params: tham số danh sách đọc từ query string
+type Params struct {
+	Page    int
+	PerPage int
+	Cursor  string
+	Sort    string
+	Values  url.Values
+}
+
+// Meta trả kèm danh sách (response.List)
+type Meta struct {
+	Total      int64  `json:"total"`
+	Page       int    `json:"page,omitempty"`
+	PerPage    int    `json:"per_page"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// cursor: vị trí bản ghi cuối của trang trước theo (cột sort, id)
+type cursor struct {
+	Sort  string          `json:"s"`
+	Value json.RawMessage `json:"v"`
+	ID    json.RawMessage `json:"id"`
+}
+
+// FromQuery đọc page / per_page / cursor / sort; filter được kiểm tra khi chạy Find theo Spec
+func FromQuery(q url.Values) (Params, error) {
+	p := Params{Page: 1, PerPage: DefaultPerPage, Cursor: q.Get("cursor"), Sort: q.Get("sort"), Values: q}
+	var errs []validate.FieldError
+	if v := q.Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > MaxPerPage {
+			errs = append(errs, validate.FieldError{Field: "per_page", Rule: "max",
+				Message: "must be between 1 and " + strconv.Itoa(MaxPerPage)})
+		} else {
+			p.PerPage = n
+		}
+	}
+	if v := q.Get("page"); v != "" {
+		// Giới hạn page để offset (page-1)*per_page không tràn số
+		maxPage := MaxOffset/p.PerPage + 1
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPage {
+			errs = append(errs, validate.FieldError{Field: "page", Rule: "max",
+				Message: "must be between 1 and " + strconv.Itoa(maxPage)})
+		}
+		p.Page = n
+	}
+	if len(errs) > 0 {
+		return p, validate.ErrValidation.WithDetails(errs)
+	}
+	return p, nil
+}
+
+// Find áp dụng filter / sort / phân trang của p lên db (đã có Where, Preload riêng của resource)
+// và ghi kết quả vào dest (con trỏ tới slice model)
+func Find(db *gorm.DB, spec Spec, p Params, dest interface{}) (*Meta, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return nil, err
+	}
+	sch := stmt.Schema
+
+	var errs []validate.FieldError
+	query := db.Model(dest)
+
+	// Filter (duyệt theo tên để thứ tự lỗi ổn định)
+	for _, name := range sortedKeys(spec.Filters) {
+		column := spec.Filters[name]
+		field := sch.LookUpField(column)
+		if field == nil {
+			panic("listquery: unknown filter column " + column)
+		}
+		if isTime(field) {
+			for _, bound := range []string{"from", "to"} {
+				param := name + "_" + bound
+				v := p.Values.Get(param)
+				if v == "" {
+					continue
+				}
+				t, dateOnly, err := parseTime(v)
+				if err != nil {
+					errs = append(errs, validate.FieldError{Field: param, Rule: "type", Message: "must be a date (YYYY-MM-DD) or RFC3339 time"})
+					continue
+				}
+				col := clause.Column{Table: clause.CurrentTable, Name: field.DBName}
+				if bound == "from" {
+					query = query.Where(clause.Gte{Column: col, Value: t})
+				} else if dateOnly {
+					query = query.Where(clause.Lt{Column: col, Value: t.AddDate(0, 0, 1)})
+				} else {
+					query = query.Where(clause.Lte{Column: col, Value: t})
+				}
+			}
+			continue
+		}
+		raw := p.Values.Get(name)
+		if raw == "" {
+			continue
+		}
+		var values []interface{}
+		for _, s := range strings.Split(raw, ",") {
+			v, err := convert(field, strings.TrimSpace(s))
+			if err != nil {
+				errs = append(errs, validate.FieldError{Field: name, Rule: "type", Message: err.Error()})
+				break
+			}
+			values = append(values, v)
+		}
+		query = query.Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Values: values})
+	}
+
+	// Sort (luôn kèm id để thứ tự ổn định và dùng được cho cursor)
+	sortBy := p.Sort
+	if sortBy == "" {
+		sortBy = spec.DefaultSort
+	}
+	desc := strings.HasPrefix(sortBy, "-")
+	column, ok := spec.Sorts[strings.TrimPrefix(sortBy, "-")]
+	if !ok {
+		errs = append(errs, validate.FieldError{Field: "sort", Rule: "oneof",
+			Message: "must be one of: " + strings.Join(sortedKeys(spec.Sorts), ", ")})
+	}
+	if len(errs) > 0 {
+		return nil, validate.ErrValidation.WithDetails(errs)
+	}
+	sortField := sch.LookUpField(column)
+	idField := sch.PrioritizedPrimaryField
+	if sortField == nil || idField == nil {
+		panic("listquery: unknown sort column " + column)
+	}
+	sortCol := clause.Column{Table: clause.CurrentTable, Name: sortField.DBName}
+	idCol := clause.Column{Table: clause.CurrentTable, Name: idField.DBName}
+
+	meta := &Meta{PerPage: p.PerPage}
+	if err := query.Session(&gorm.Session{}).Count(&meta.Total).Error; err != nil {
+		return nil, err
+	}
+
+	if p.Cursor != "" {
+		after, err := decodeCursor(p.Cursor, sortBy, sortField, idField)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(after.condition(sortCol, idCol, desc))
+	} else {
+		meta.Page = p.Page
+		query = query.Offset((p.Page - 1) * p.PerPage)
+	}
+
+	// Lấy dư 1 bản ghi để biết còn trang sau hay không
+	err := query.
+		Order(clause.OrderBy{Columns: []clause.OrderByColumn{{Column: sortCol, Desc: desc}, {Column: idCol, Desc: desc}}}).
+		Limit(p.PerPage + 1).
+		Find(dest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rows := reflect.ValueOf(dest).Elem()
+	if rows.IsNil() {
+		rows.Set(reflect.MakeSlice(rows.Type(), 0, 0))
+	}
+	if rows.Len() > p.PerPage {
+		rows.Set(rows.Slice(0, p.PerPage))
+		last := rows.Index(p.PerPage - 1)
+		next, err := encodeCursor(sortBy, sortField, idField, last)
+		if err != nil {
+			return nil, err
+		}
+		meta.NextCursor = next
+	}
+	return meta, nil
+}
+
+type position struct {
+	value interface{}
+	id    interface{}
+}
+
+// condition: (sort, id) đứng sau vị trí cursor theo chiều sắp xếp
+func (pos position) condition(sortCol, idCol clause.Column, desc bool) clause.Expression {
+	if desc {
+		return clause.Or(
+			clause.Lt{Column: sortCol, Value: pos.value},
+			clause.And(clause.Eq{Column: sortCol, Value: pos.value}, clause.Lt{Column: idCol, Value: pos.id}),
+		)
+	}
+	return clause.Or(
+		clause.Gt{Column: sortCol, Value: pos.value},
+		clause.And(clause.Eq{Column: sortCol, Value: pos.value}, clause.Gt{Column: idCol, Value: pos.id}),
+	)
+}
+
+var errInvalidCursor = validate.ErrValidation.WithDetails([]validate.FieldError{
+	{Field: "cursor", Rule: "cursor", Message: "is invalid or does not match sort"},
+})
+
+func encodeCursor(sortBy string, sortField, idField *schema.Field, row reflect.Value) (string, error) {
+	value, _ := sortField.ValueOf(context.Background(), row)
+	id, _ := idField.ValueOf(context.Background(), row)
+	v, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	i, err := json.Marshal(id)
+	if err != nil {
+		return "", err
+	}
+	b, err := json.Marshal(cursor{Sort: sortBy, Value: v, ID: i})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeCursor giải mã cursor về đúng kiểu Go của cột để so sánh được trên mọi dialect
+func decodeCursor(s, sortBy string, sortField, idField *schema.Field) (position, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return position{}, errInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sortBy {
+		return position{}, errInvalidCursor
+	}
+	value := reflect.New(sortField.FieldType)
+	id := reflect.New(idField.FieldType)
+	if json.Unmarshal(c.Value, value.Interface()) != nil || json.Unmarshal(c.ID, id.Interface()) != nil {
+		return position{}, errInvalidCursor
+	}
+	return position{value: value.Elem().Interface(), id: id.Elem().Interface()}, nil
+}
+
+var (
+	errNotNumber = errors.New("must be a number")
+	errNotBool   = errors.New("must be true or false")
+)
+
+// convert đổi giá trị filter (string) sang kiểu của cột
+func convert(field *schema.Field, s string) (interface{}, error) {
+	t := field.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		return nil, errNotNumber
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return n, nil
+		}
+		return nil, errNotNumber
+	case reflect.Float32, reflect.Float64:
+		if n, err := strconv.ParseFloat(s, 64); err == nil {
+			return n, nil
+		}
+		return nil, errNotNumber
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b, nil
+		}
+		return nil, errNotBool
+	default:
+		return s, nil
+	}
+}
+
+func isTime(field *schema.Field) bool {
+	t := field.FieldType
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(time.Time{})
+}
+
+// parseTime nhận YYYY-MM-DD (dateOnly) hoặc RFC3339
+func parseTime(s string) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t, false, err
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,10 +1,14 @@
 package middlewares
 
 import (
+	"backend/internal/apperr"
+	"backend/internal/repository"
+	"backend/internal/response"
 	"backend/internal/service"
 	"context"
 	"net/http"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 )
@@ -12,7 +16,14 @@ import (
 type contextKey string
 
 const userContextKey = contextKey("user")
+const permissionsContextKey = contextKey("permissions")
 
+// requestPermissions: quyền của user được resolve từ DB tối đa 1 lần mỗi request
+type requestPermissions struct {
+	once sync.Once
+	set  service.PermissionSet
+	err  error
+}
 
 func GetUserFromContext(r *http.Request) *service.Claims {
 	claims, ok := r.Context().Value(userContextKey).(*service.Claims)
@@ -22,33 +33,140 @@ func GetUserFromContext(r *http.Request) *service.Claims {
 	return claims
 }
 
+var (
+	errMissingToken = apperr.Unauthorized("Missing token").WithCode("missing_token")
+	errInvalidToken = apperr.Unauthorized("Invalid token").WithCode("invalid_token")
+	errTokenRevoked = apperr.Unauthorized("Token revoked").WithCode("token_revoked")
+	errUserDeleted  = apperr.Unauthorized("User no longer exists").WithCode("user_deleted")
+	errSecondFactor = apperr.Unauthorized("Second factor required").WithCode("second_factor_required")
+	errForbidden    = apperr.Forbidden("Forbidden")
+	errUnauthorized = apperr.Unauthorized("Unauthorized")
+)
+
+// authenticate parse Bearer token và kiểm tra jti không nằm trong danh sách thu hồi.
+// Token không có jti không thu hồi được nên bị từ chối; user đã bị xóa thì token cũ cũng hết hiệu lực ngay.
+func authenticate(tokenStr string) (*service.Claims, error) {
+	claims, err := service.ParseToken(tokenStr)
+	if err != nil || claims.ID == "" {
+		return nil, errInvalidToken
+	}
+	revoked, err := repository.IsAccessTokenRevoked(claims.ID)
+	if err != nil || revoked {
+		return nil, errTokenRevoked
+	}
+	exists, err := repository.UserExists(claims.UserID)
+	if err != nil {
+		return nil, apperr.Internal("Failed to load user", err)
+	}
+	if !exists {
+		return nil, errUserDeleted
+	}
+	return claims, nil
+}
+
+func withClaims(r *http.Request, claims *service.Claims) *http.Request {
+	ctx := context.WithValue(r.Context(), userContextKey, claims)
+	ctx = context.WithValue(ctx, permissionsContextKey, &requestPermissions{})
+	return r.WithContext(ctx)
+}
+
 func JWTMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, `{"error":"Missing token"}`, http.StatusUnauthorized)
+			response.Error(w, errMissingToken)
 			return
 		}
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims, err := service.ParseToken(tokenStr)
+		claims, err := authenticate(tokenStr)
 		if err != nil {
-			http.Error(w, `{"error":"Invalid token"}`, http.StatusUnauthorized)
+			response.Error(w, err)
+			return
+		}
+		// Partial token (chưa qua 2FA) không được gọi API thường
+		if claims.Stage != "" {
+			response.Error(w, errSecondFactor)
 			return
 		}
 
 		// Gắn claims vào context
-		ctx := context.WithValue(r.Context(), userContextKey, claims)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, withClaims(r, claims))
+	})
+}
+
+// OptionalJWTMiddleware gắn claims vào context nếu có token hợp lệ, không chặn request khi thiếu token
+// (dùng cho các API storefront mà khách vãng lai cũng gọi được, ví dụ giỏ hàng)
+func OptionalJWTMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader := r.Header.Get("Authorization")
+		if authHeader == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+
+		claims, err := authenticate(tokenStr)
+		if err != nil {
+			response.Error(w, err)
+			return
+		}
+		if claims.Stage != "" {
+			response.Error(w, errSecondFactor)
+			return
+		}
+
+		next.ServeHTTP(w, withClaims(r, claims))
 	})
 }
 
+// MFAMiddleware dùng cho các API 2FA: chấp nhận partial token (bước 2 đăng nhập);
+// nếu allowFull = true thì token đầy đủ cũng được (vd enrol TOTP khi đã đăng nhập)
+func MFAMiddleware(allowFull bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeader := r.Header.Get("Authorization")
+			if authHeader == "" {
+				response.Error(w, errMissingToken)
+				return
+			}
+			tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+
+			claims, err := authenticate(tokenStr)
+			if err != nil {
+				response.Error(w, err)
+				return
+			}
+			if claims.Stage != service.StageMFA && !(allowFull && claims.Stage == "") {
+				response.Error(w, errInvalidToken)
+				return
+			}
+
+			next.ServeHTTP(w, withClaims(r, claims))
+		})
+	}
+}
+
+// GetPermissionsFromContext trả về quyền của user hiện tại (cache theo request)
+func GetPermissionsFromContext(r *http.Request) (service.PermissionSet, error) {
+	claims := GetUserFromContext(r)
+	cache, ok := r.Context().Value(permissionsContextKey).(*requestPermissions)
+	if claims == nil || !ok {
+		return service.PermissionSet{}, nil
+	}
+	cache.once.Do(func() {
+		codes, err := repository.GetPermissionCodes(claims.RoleIDs, claims.Role)
+		cache.set, cache.err = service.NewPermissionSet(codes), err
+	})
+	return cache.set, cache.err
+}
+
 func RoleMiddleware(roles ...string) mux.MiddlewareFunc {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			claims := GetUserFromContext(r)
 			if claims == nil {
-				http.Error(w, `{"error":"Unauthorized"}`, http.StatusUnauthorized)
+				response.Error(w, errUnauthorized)
 				return
 			}
 			for _, role := range roles {
@@ -57,7 +175,29 @@ func RoleMiddleware(roles ...string) mux.MiddlewareFunc {
 					return
 				}
 			}
-			http.Error(w, `{"error":"Forbidden"}`, http.StatusForbidden)
+			response.Error(w, errForbidden)
 		})
 	}
 }
+
+// RequirePermission bọc handler, chỉ cho qua khi role trong JWT có capability perm.
+// Phải đặt sau JWTMiddleware.
+func RequirePermission(perm string, next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims := GetUserFromContext(r)
+		if claims == nil {
+			response.Error(w, errUnauthorized)
+			return
+		}
+		perms, err := GetPermissionsFromContext(r)
+		if err != nil {
+			response.Error(w, apperr.Internal("Failed to load permissions", err))
+			return
+		}
+		if !perms.Has(perm) {
+			response.Error(w, errForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
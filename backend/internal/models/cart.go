@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Cart: mỗi customer có 1 giỏ hàng; giỏ của khách vãng lai được định danh bằng GuestToken
+type Cart struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     *uint     `gorm:"uniqueIndex" json:"user_id"`
+	GuestToken *string   `gorm:"type:varchar(64);uniqueIndex" json:"-"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+
+	// Tính lại mỗi lần đọc, không lưu DB
+	Total float64 `gorm:"-" json:"total"`
+
+	Items []CartItem `gorm:"foreignKey:CartID;constraint:OnDelete:CASCADE" json:"items"`
+}
+
+type CartItem struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	CartID    uint      `gorm:"uniqueIndex:idx_cart_variant;not null" json:"cart_id"`
+	VariantID uint      `gorm:"uniqueIndex:idx_cart_variant;not null" json:"variant_id"`
+	Quantity  int       `gorm:"not null" json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Giá lấy theo variant + Product.Discount tại thời điểm đọc
+	UnitPrice float64 `gorm:"-" json:"unit_price"`
+	Subtotal  float64 `gorm:"-" json:"subtotal"`
+
+	Variant ProductVariant `gorm:"foreignKey:VariantID" json:"variant"`
+}
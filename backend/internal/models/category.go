@@ -1,12 +1,18 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Category struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"unique;not null" json:"name"`
-	GroupName string    `gorm:"type:enum('Đồ nam','Đồ nữ','Đồ thể thao','Trẻ em','Phụ kiện');not null;default:'Đồ nam'" json:"group_name"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"unique;not null" json:"name"`
+	GroupName string         `gorm:"type:varchar(50);not null;default:'Đồ nam';check:chk_categories_group_name,group_name IN ('Đồ nam','Đồ nữ','Đồ thể thao','Trẻ em','Phụ kiện')" json:"group_name"`
+	Version   uint           `gorm:"not null;default:1" json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	Products []Product `gorm:"foreignKey:CategoryID"`
 }
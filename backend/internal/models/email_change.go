@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// EmailChangeRequest: yêu cầu đổi email chờ xác nhận từ địa chỉ mới, token chỉ lưu hash
+type EmailChangeRequest struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	NewEmail  string     `gorm:"type:varchar(255);not null" json:"new_email"`
+	TokenHash string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
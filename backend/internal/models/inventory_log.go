@@ -5,7 +5,7 @@ import "time"
 type InventoryLog struct {
 	ID        uint      `gorm:"primaryKey" json:"id"`
 	VariantID uint      `json:"variant_id"`
-	ChangeType string   `gorm:"type:enum('import','sale','return','adjust')" json:"change_type"`
+	ChangeType string   `gorm:"type:varchar(20);check:chk_inventory_logs_change_type,change_type IN ('import','sale','return','adjust','purchase_correction')" json:"change_type"`
 	Quantity  int       `json:"quantity"`
 	Note      string    `json:"note"`
 	CreatedAt time.Time `json:"created_at"`
@@ -3,16 +3,21 @@ package models
 import "time"
 
 type Order struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	CustomerID    uint      `json:"customer_id"`
-	StaffID       *uint     `json:"staff_id"`
-	Status        string    `gorm:"type:enum('pending','confirmed','shipped','completed','cancelled');default:'pending'" json:"status"`
-	PaymentMethod string    `gorm:"type:enum('cod','online');default:'cod'" json:"payment_method"`
-	Total         float64   `json:"total"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	CustomerID    uint       `json:"customer_id"`
+	StaffID       *uint      `json:"staff_id"`
+	Status        string     `gorm:"type:varchar(20);default:'pending';check:chk_orders_status,status IN ('pending','confirmed','shipped','completed','cancelled')" json:"status"`
+	PaymentMethod string     `gorm:"type:varchar(20);default:'cod';check:chk_orders_payment_method,payment_method IN ('cod','online')" json:"payment_method"`
+	Total         float64    `json:"total"`
+	CreatedAt     time.Time  `json:"created_at"`
+	CompletedAt   *time.Time `json:"completed_at"`
+
+	// Snapshot địa chỉ giao hàng lúc đặt, không đổi khi customer sửa sổ địa chỉ
+	Shipping ShippingAddress `gorm:"embedded;embeddedPrefix:shipping_" json:"shipping_address"`
 
 	Customer User `gorm:"foreignKey:CustomerID"`
 	Staff    User `gorm:"foreignKey:StaffID"`
 
-	Items []OrderItem `gorm:"foreignKey:OrderID"`
+	Items   []OrderItem          `gorm:"foreignKey:OrderID"`
+	History []OrderStatusHistory `gorm:"foreignKey:OrderID" json:"history,omitempty"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// OrderStatusHistory ghi lại mỗi lần order đổi trạng thái (ai đổi, từ đâu sang đâu, ghi chú)
+type OrderStatusHistory struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OrderID   uint      `gorm:"index;not null" json:"order_id"`
+	ActorID   *uint     `json:"actor_id"`
+	ActorRole string    `gorm:"type:varchar(20)" json:"actor_role"`
+	OldStatus string    `gorm:"type:varchar(20)" json:"old_status"`
+	NewStatus string    `gorm:"type:varchar(20);not null" json:"new_status"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+
+	Actor *User `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+}
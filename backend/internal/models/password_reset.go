@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PasswordResetToken: token đặt lại mật khẩu dùng 1 lần, chỉ lưu hash SHA-256
+type PasswordResetToken struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	TokenHash string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
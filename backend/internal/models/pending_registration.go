@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PendingRegistration: đăng ký chờ xác nhận email. Token xác nhận là chuỗi opaque,
+// chỉ lưu hash; user thật chỉ được tạo khi bấm link xác nhận.
+type PendingRegistration struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	Username     string     `gorm:"type:varchar(255);not null" json:"username"`
+	Email        string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	Phone        string     `gorm:"type:varchar(20)" json:"phone"`
+	Address      string     `gorm:"type:varchar(255)" json:"address"`
+	PasswordHash string     `gorm:"not null" json:"-"`
+	TokenHash    string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	ConfirmedAt  *time.Time `json:"confirmed_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Product struct {
 	ID              uint    `gorm:"primaryKey" json:"id"`
@@ -11,8 +15,11 @@ type Product struct {
 	Price           float64 `json:"price"`
 	Discount        float64 `json:"discount"`
 	DiscountedPrice float64 `json:"discounted_price" gorm:"->"`
+	IsPublished     bool    `gorm:"default:true" json:"is_published"`
+	Version         uint    `gorm:"not null;default:1" json:"version"`
 
-	CreatedAt time.Time `json:"created_at"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	Category Category         `gorm:"foreignKey:CategoryID"`
 	Variants []ProductVariant `gorm:"foreignKey:ProductID"`
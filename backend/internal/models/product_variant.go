@@ -9,6 +9,7 @@ type ProductVariant struct {
 	Stock     int     `json:"stock"`
 	SKU       string  `json:"sku"`
     Image       string    `json:"image"`
+	Version   uint    `gorm:"not null;default:1" json:"version"`
 	Product Product `gorm:"foreignKey:ProductID"`
 	OrderItems    []OrderItem    `gorm:"foreignKey:VariantID"`
 	Purchases     []Purchase     `gorm:"foreignKey:VariantID"`
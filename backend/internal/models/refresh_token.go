@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// RefreshToken: refresh token dạng opaque, chỉ lưu hash SHA-256. Mỗi lần refresh token cũ bị
+// thu hồi và thay bằng token mới (rotation); dùng lại token đã thu hồi sẽ thu hồi mọi phiên.
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"index;not null" json:"user_id"`
+	TokenHash    string     `gorm:"type:char(64);uniqueIndex;not null" json:"-"`
+	IP           string     `json:"ip"`
+	UserAgent    string     `json:"user_agent"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	ReplacedByID *uint      `json:"replaced_by_id"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// RevokedToken: danh sách access token (theo jti) đã bị thu hồi trước khi hết hạn
+type RevokedToken struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	JTI       string    `gorm:"column:jti;type:varchar(64);uniqueIndex;not null" json:"jti"`
+	UserID    uint      `gorm:"index" json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
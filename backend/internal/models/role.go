@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Role: nhóm quyền. Các role hệ thống (admin/staff/customer) trùng tên với User.Role
+// và không được xóa; role tùy biến (vd "warehouse clerk") gán thêm qua bảng user_roles.
+type Role struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(50);unique;not null" json:"name"`
+	Description string    `json:"description"`
+	IsSystem    bool      `gorm:"default:false" json:"is_system"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Permissions []Permission `gorm:"many2many:role_permissions" json:"permissions"`
+}
+
+// Permission: 1 capability, vd "orders:update"
+type Permission struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Code        string `gorm:"type:varchar(50);unique;not null" json:"code"`
+	Description string `json:"description"`
+}
+
+// RolePermission: bảng nối role ↔ permission
+type RolePermission struct {
+	RoleID       uint `gorm:"primaryKey" json:"role_id"`
+	PermissionID uint `gorm:"primaryKey" json:"permission_id"`
+}
+
+// UserRole: bảng nối user ↔ role tùy biến
+type UserRole struct {
+	UserID uint `gorm:"primaryKey" json:"user_id"`
+	RoleID uint `gorm:"primaryKey" json:"role_id"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ShopVariant là biến thể hiển thị cho khách (không lộ số lượng tồn kho)
+type ShopVariant struct {
+	ID      uint    `json:"id"`
+	Size    string  `json:"size"`
+	Color   string  `json:"color"`
+	Price   float64 `json:"price"` // giá bán sau discount, giống UnitPrice của giỏ hàng
+	SKU     string  `json:"sku"`
+	Image   string  `json:"image"`
+	InStock bool    `json:"in_stock"`
+}
+
+// ShopProduct là sản phẩm hiển thị trên storefront
+type ShopProduct struct {
+	ID              uint          `json:"id"`
+	Name            string        `json:"name"`
+	Description     string        `json:"description"`
+	Image           string        `json:"image"`
+	Price           float64       `json:"price"`
+	Discount        float64       `json:"discount"`
+	DiscountedPrice float64       `json:"discounted_price"`
+	CategoryID      uint          `json:"category_id"`
+	CategoryName    string        `json:"category_name"`
+	GroupName       string        `json:"group_name"`
+	InStock         bool          `json:"in_stock"`
+	CreatedAt       time.Time     `json:"created_at"`
+	Variants        []ShopVariant `json:"variants"`
+}
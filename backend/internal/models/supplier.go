@@ -1,14 +1,20 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Supplier struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      string    `gorm:"not null" json:"name"`
-	Phone     string    `json:"phone"`
-	Email     string    `json:"email"`
-	Address   string    `json:"address"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `gorm:"not null" json:"name"`
+	Phone     string         `json:"phone"`
+	Email     string         `json:"email"`
+	Address   string         `json:"address"`
+	Version   uint           `gorm:"not null;default:1" json:"version"`
+	CreatedAt time.Time      `json:"created_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
 
 	Purchases []Purchase `gorm:"foreignKey:SupplierID"`
 }
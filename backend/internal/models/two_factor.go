@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// UserTOTP: cấu hình TOTP của user. Secret chỉ có hiệu lực khi Enabled (đã xác nhận bằng 1 mã đúng).
+type UserTOTP struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	UserID       uint       `gorm:"uniqueIndex;not null" json:"user_id"`
+	Secret       string     `gorm:"type:varchar(64);not null" json:"-"`
+	Enabled      bool       `gorm:"default:false" json:"enabled"`
+	LastUsedStep int64      `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
+
+// BackupCode: mã dự phòng dùng 1 lần khi mất thiết bị TOTP, chỉ lưu hash
+type BackupCode struct {
+	ID        uint       `gorm:"primaryKey" json:"id"`
+	UserID    uint       `gorm:"index;not null" json:"user_id"`
+	CodeHash  string     `gorm:"type:char(64);not null" json:"-"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// ShippingAddress: địa chỉ giao hàng theo 3 cấp hành chính Việt Nam (tỉnh/huyện/xã)
+type ShippingAddress struct {
+	RecipientName string `gorm:"type:varchar(255)" json:"recipient_name"`
+	Phone         string `gorm:"type:varchar(20)" json:"phone"`
+	Province      string `gorm:"type:varchar(100)" json:"province"`
+	District      string `gorm:"type:varchar(100)" json:"district"`
+	Ward          string `gorm:"type:varchar(100)" json:"ward"`
+	Street        string `gorm:"type:varchar(255)" json:"street"`
+}
+
+// UserAddress: sổ địa chỉ của customer, mỗi user có tối đa 1 địa chỉ mặc định
+type UserAddress struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	UserID    uint            `gorm:"index;not null" json:"user_id"`
+	Address   ShippingAddress `gorm:"embedded" json:"address"`
+	IsDefault bool            `gorm:"default:false" json:"is_default"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
@@ -1,57 +1,112 @@
 package admin
 
 import (
-	"backend/configs"
 	"backend/internal/models"
+
+	"gorm.io/gorm"
 )
 
+// CategoryRepository: truy cập dữ liệu category cho trang admin
+type CategoryRepository interface {
+	GetAllCategories() ([]models.Category, error)
+	GetCategoryDetail(id uint) (*models.Category, error)
+	CreateCategory(c *models.Category) (*models.Category, error)
+	UpdateCategory(id uint, newData *models.Category, version uint) (*models.Category, error)
+	DeleteCategory(id uint) error
+	GetTrashedCategories() ([]models.Category, error)
+	RestoreCategory(id uint) error
+	PurgeCategory(id uint) error
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
 // GetAllCategories trả về danh sách (không include soft-deleted)
-func GetAllCategories() ([]models.Category, error) {
+func (r *categoryRepository) GetAllCategories() ([]models.Category, error) {
 	var categories []models.Category
-	if err := configs.DB.Find(&categories).Error; err != nil {
+	if err := r.db.Find(&categories).Error; err != nil {
 		return nil, err
 	}
 	return categories, nil
 }
 
 // GetCategoryDetail lấy 1 category kèm products (nếu cần)
-func GetCategoryDetail(id uint) (*models.Category, error) {
+func (r *categoryRepository) GetCategoryDetail(id uint) (*models.Category, error) {
 	var category models.Category
 	// nếu muốn preload products:
-	if err := configs.DB.Preload("Products").First(&category, id).Error; err != nil {
+	if err := r.db.Preload("Products").First(&category, id).Error; err != nil {
 		return nil, err
 	}
 	return &category, nil
 }
 
 // CreateCategory tạo mới
-func CreateCategory(c *models.Category) (*models.Category, error) {
-	if err := configs.DB.Create(c).Error; err != nil {
+func (r *categoryRepository) CreateCategory(c *models.Category) (*models.Category, error) {
+	if err := r.db.Create(c).Error; err != nil {
 		return nil, err
 	}
 	return c, nil
 }
 
-
 // UpdateCategory cập nhật tên (hoặc các trường khác)
-func UpdateCategory(id uint, newData *models.Category) (*models.Category, error) {
-	var category models.Category
-	if err := configs.DB.First(&category, id).Error; err != nil {
+// (chỉ ghi khi version còn khớp, xem updateVersioned)
+func (r *categoryRepository) UpdateCategory(id uint, newData *models.Category, version uint) (*models.Category, error) {
+	// Cập nhật những field cho phép
+	updates := map[string]interface{}{"name": newData.Name}
+	if newData.GroupName != "" {
+		updates["group_name"] = newData.GroupName
+	}
+	if err := updateVersioned(r.db, &models.Category{}, id, version, updates); err != nil {
 		return nil, err
 	}
 
-	// Cập nhật những field cho phép
-	category.Name = newData.Name
-	category.GroupName = newData.GroupName
-
-	if err := configs.DB.Save(&category).Error; err != nil {
+	var category models.Category
+	if err := r.db.First(&category, id).Error; err != nil {
 		return nil, err
 	}
 	return &category, nil
 }
 
+// DeleteCategory xóa (soft-delete). Xóa vĩnh viễn qua PurgeCategory.
+// Chặn nếu còn product chưa xóa, tránh product "mồ côi" biến mất khỏi cửa hàng.
+func (r *categoryRepository) DeleteCategory(id uint) error {
+	if err := checkDependents("category", id,
+		dependentCheck{"products", r.db.Model(&models.Product{}).Where("category_id = ?", id)},
+	); err != nil {
+		return err
+	}
+	res := r.db.Delete(&models.Category{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetTrashedCategories: danh sách category đã soft-delete
+func (r *categoryRepository) GetTrashedCategories() ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.Scopes(onlyTrashed).Order("deleted_at desc").Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) RestoreCategory(id uint) error {
+	return restoreTrashed(r.db, &models.Category{}, id)
+}
 
-// DeleteCategory xóa (soft-delete). Nếu muốn hard-delete dùng Unscoped().Delete(...)
-func DeleteCategory(id uint) error {
-	return configs.DB.Delete(&models.Category{}, id).Error
+// PurgeCategory bị chặn nếu còn product tham chiếu (kể cả product trong thùng rác)
+func (r *categoryRepository) PurgeCategory(id uint) error {
+	if err := checkDependents("category", id,
+		dependentCheck{"products", r.db.Unscoped().Model(&models.Product{}).Where("category_id = ?", id)},
+	); err != nil {
+		return err
+	}
+	return purgeTrashed(r.db, &models.Category{}, id)
 }
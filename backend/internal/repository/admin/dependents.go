@@ -0,0 +1,57 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+
+	"gorm.io/gorm"
+)
+
+// ErrHasDependents: không xóa được vì còn bản ghi khác tham chiếu tới
+var ErrHasDependents = apperr.Conflict("resource has dependent records").WithCode("has_dependents")
+
+// maxDependentIDs: số id tối đa trả về cho mỗi loại bản ghi phụ thuộc
+const maxDependentIDs = 20
+
+// Dependent: 1 loại bản ghi đang tham chiếu tới bản ghi muốn xóa
+type Dependent struct {
+	Type  string `json:"type"`
+	Count int64  `json:"count"`
+	IDs   []uint `json:"ids"`
+}
+
+// DependentsDetails là phần details của lỗi ErrHasDependents, controller trả 409 kèm danh sách này
+type DependentsDetails struct {
+	Resource   string      `json:"resource"`
+	ID         uint        `json:"id"`
+	Dependents []Dependent `json:"dependents"`
+}
+
+// dependentCheck: query trên bảng phụ thuộc (đã lọc theo bản ghi cha), Pluck theo cột id
+type dependentCheck struct {
+	Type  string
+	Query *gorm.DB
+}
+
+// checkDependents chạy từng query, trả ErrHasDependents (kèm DependentsDetails) nếu có ít nhất 1 bản ghi phụ thuộc
+func checkDependents(resource string, id uint, checks ...dependentCheck) error {
+	var found []Dependent
+	for _, c := range checks {
+		query := c.Query.Session(&gorm.Session{})
+		var count int64
+		if err := query.Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			continue
+		}
+		var ids []uint
+		if err := query.Order("id").Limit(maxDependentIDs).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		found = append(found, Dependent{Type: c.Type, Count: count, IDs: ids})
+	}
+	if len(found) > 0 {
+		return ErrHasDependents.WithDetails(DependentsDetails{Resource: resource, ID: id, Dependents: found})
+	}
+	return nil
+}
@@ -1,72 +1,101 @@
 package admin
 
 import (
-	"backend/configs"
+	"backend/internal/listquery"
 	"backend/internal/models"
-	"errors"
+
+	"gorm.io/gorm"
 )
 
+// InventoryRepository: truy cập dữ liệu inventory log cho trang admin
+type InventoryRepository interface {
+	GetAllInventoryLogs(p listquery.Params) ([]models.InventoryLog, *listquery.Meta, error)
+	GetInventoryLogDetail(id uint) (*models.InventoryLog, error)
+	CreateInventoryLog(log *models.InventoryLog) (*models.InventoryLog, error)
+	UpdateInventoryLog(id uint, newData *models.InventoryLog) (*models.InventoryLog, error)
+	DeleteInventoryLog(id uint) error
+}
+
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &inventoryRepository{db: db}
+}
+
+// inventoryLogListSpec: cột được sort / filter ở GET /api/admin/inventory_logs
+var inventoryLogListSpec = listquery.Spec{
+	Sorts:       map[string]string{"id": "id", "created_at": "created_at", "quantity": "quantity"},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"variant_id": "variant_id", "change_type": "change_type", "created": "created_at"},
+}
+
 // GET ALL INVENTORY LOGS
-func GetAllInventoryLogs() ([]models.InventoryLog, error) {
+func (r *inventoryRepository) GetAllInventoryLogs(p listquery.Params) ([]models.InventoryLog, *listquery.Meta, error) {
 	var logs []models.InventoryLog
-	err := configs.DB.Preload("Variant").Find(&logs).Error
-	return logs, err
+	meta, err := listquery.Find(r.db.Preload("Variant"), inventoryLogListSpec, p, &logs)
+	return logs, meta, err
 }
 
 // GET INVENTORY LOG DETAIL
-func GetInventoryLogDetail(id uint) (*models.InventoryLog, error) {
+func (r *inventoryRepository) GetInventoryLogDetail(id uint) (*models.InventoryLog, error) {
 	var log models.InventoryLog
-	err := configs.DB.Preload("Variant").First(&log, id).Error
+	err := r.db.Preload("Variant").First(&log, id).Error
 	return &log, err
 }
 
-// CREATE INVENTORY LOG + UPDATE STOCK
-func CreateInventoryLog(log *models.InventoryLog) (*models.InventoryLog, error) {
-	// 1. Lấy variant
-	var variant models.ProductVariant
-	if err := configs.DB.First(&variant, log.VariantID).Error; err != nil {
-		return nil, errors.New("variant not found")
-	}
+// CREATE INVENTORY LOG + UPDATE STOCK (1 transaction, khóa variant)
+func (r *inventoryRepository) CreateInventoryLog(log *models.InventoryLog) (*models.InventoryLog, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// 1. Lấy variant (SELECT ... FOR UPDATE)
+		variant, err := lockVariant(tx, log.VariantID)
+		if err != nil {
+			return err
+		}
 
-	// 2. Cập nhật stock dựa trên change_type
-	switch log.ChangeType {
-	case "import", "return":
-		variant.Stock += log.Quantity
-	case "sale":
-		if variant.Stock < log.Quantity {
-			return nil, errors.New("insufficient stock")
+		// 2. Cập nhật stock dựa trên change_type
+		stock := variant.Stock
+		switch log.ChangeType {
+		case "import", "return":
+			stock += log.Quantity
+		case "sale":
+			if stock < log.Quantity {
+				return ErrInsufficientStock
+			}
+			stock -= log.Quantity
+		case "adjust":
+			stock = log.Quantity
+		default:
+			return ErrInvalidChangeType
 		}
-		variant.Stock -= log.Quantity
-	case "adjust":
-		variant.Stock = log.Quantity
-	default:
-		return nil, errors.New("invalid change type")
-	}
 
-	// 3. Lưu log và cập nhật variant
-	if err := configs.DB.Save(&variant).Error; err != nil {
-		return nil, err
-	}
-	if err := configs.DB.Create(log).Error; err != nil {
+		// 3. Lưu log và cập nhật variant
+		if err := setVariantStock(tx, variant, stock); err != nil {
+			return err
+		}
+		return tx.Omit("Variant").Create(log).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return log, nil
 }
 
 // UPDATE INVENTORY LOG (chỉ note, không thay đổi stock)
-func UpdateInventoryLog(id uint, newData *models.InventoryLog) (*models.InventoryLog, error) {
+func (r *inventoryRepository) UpdateInventoryLog(id uint, newData *models.InventoryLog) (*models.InventoryLog, error) {
 	var log models.InventoryLog
-	if err := configs.DB.First(&log, id).Error; err != nil {
+	if err := r.db.First(&log, id).Error; err != nil {
 		return nil, err
 	}
 	log.Note = newData.Note
-	if err := configs.DB.Save(&log).Error; err != nil {
+	if err := r.db.Save(&log).Error; err != nil {
 		return nil, err
 	}
 	return &log, nil
 }
 
 // DELETE INVENTORY LOG (không tự động rollback stock)
-func DeleteInventoryLog(id uint) error {
-	return configs.DB.Delete(&models.InventoryLog{}, id).Error
+func (r *inventoryRepository) DeleteInventoryLog(id uint) error {
+	return r.db.Delete(&models.InventoryLog{}, id).Error
 }
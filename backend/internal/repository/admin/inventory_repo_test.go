@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"backend/internal/models"
+	"errors"
+	"testing"
+)
+
+func TestCreateInventoryLog(t *testing.T) {
+	tests := []struct {
+		name       string
+		changeType string
+		quantity   int
+		wantStock  int
+		wantErr    error
+	}{
+		{"import adds", "import", 3, 8, nil},
+		{"return adds", "return", 2, 7, nil},
+		{"sale subtracts", "sale", 5, 0, nil},
+		{"sale beyond stock", "sale", 6, 5, ErrInsufficientStock},
+		{"adjust sets stock", "adjust", 12, 12, nil},
+		{"unknown change type", "gift", 1, 5, ErrInvalidChangeType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t, 5)
+			repo := NewInventoryRepository(f.db)
+			variantID := f.variants[0].ID
+
+			_, err := repo.CreateInventoryLog(&models.InventoryLog{VariantID: variantID, ChangeType: tt.changeType, Quantity: tt.quantity})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := f.stock(t, variantID); got != tt.wantStock {
+				t.Errorf("stock = %d, want %d", got, tt.wantStock)
+			}
+
+			// Lỗi thì không được ghi log (transaction rollback)
+			var count int64
+			f.db.Model(&models.InventoryLog{}).Where("variant_id = ?", variantID).Count(&count)
+			if want := map[bool]int64{true: 0, false: 1}[tt.wantErr != nil]; count != want {
+				t.Errorf("log count = %d, want %d", count, want)
+			}
+		})
+	}
+}
+
+func TestCreateInventoryLogUnknownVariant(t *testing.T) {
+	f := newFixture(t, 5)
+	repo := NewInventoryRepository(f.db)
+
+	_, err := repo.CreateInventoryLog(&models.InventoryLog{VariantID: 999, ChangeType: "import", Quantity: 1})
+	if !errors.Is(err, ErrVariantNotFound) {
+		t.Fatalf("err = %v, want ErrVariantNotFound", err)
+	}
+}
+
+// Mọi thay đổi tồn kho tăng version để form sửa variant cũ (If-Match) bị từ chối
+func TestCreateInventoryLogBumpsVariantVersion(t *testing.T) {
+	f := newFixture(t, 5)
+	repo := NewInventoryRepository(f.db)
+	v := f.variants[0]
+
+	if _, err := repo.CreateInventoryLog(&models.InventoryLog{VariantID: v.ID, ChangeType: "import", Quantity: 1}); err != nil {
+		t.Fatalf("CreateInventoryLog: %v", err)
+	}
+	products := NewProductRepository(f.db)
+	_, err := products.UpdateVariant(v.ProductID, v.ID, &models.ProductVariant{SKU: v.SKU, Stock: 0}, v.Version)
+	if !errors.Is(err, ErrVersionConflict) {
+		t.Fatalf("err = %v, want ErrVersionConflict", err)
+	}
+}
@@ -2,28 +2,65 @@
 package admin
 
 import (
-	"backend/configs"
+	"backend/internal/listquery"
 	"backend/internal/models"
+	"backend/internal/service"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// Lấy tất cả orders
-func GetAllOrders() ([]models.Order, error) {
+// OrderRepository: truy cập dữ liệu order cho trang admin
+type OrderRepository interface {
+	GetAllOrders(p listquery.Params) ([]models.Order, *listquery.Meta, error)
+	GetOrderDetail(id uint) (*models.Order, error)
+	UpdateOrderStatus(id uint, status string, actor *service.Claims, note string) error
+	ChangeOrderStatus(tx *gorm.DB, order *models.Order, status string, actor *service.Claims, note string) error
+}
+
+type orderRepository struct {
+	db *gorm.DB
+}
+
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+// orderListSpec: cột được sort / filter ở GET /api/admin/orders
+var orderListSpec = listquery.Spec{
+	Sorts:       map[string]string{"id": "id", "created_at": "created_at", "total": "total", "status": "status"},
+	DefaultSort: "-created_at",
+	Filters: map[string]string{
+		"status":         "status",
+		"customer_id":    "customer_id",
+		"staff_id":       "staff_id",
+		"payment_method": "payment_method",
+		"created":        "created_at",
+	},
+}
+
+// Lấy orders theo trang, lọc theo status / customer / ngày tạo... nếu có
+func (r *orderRepository) GetAllOrders(p listquery.Params) ([]models.Order, *listquery.Meta, error) {
 	var orders []models.Order
-	err := configs.DB.
-		Preload("Customer").
-		Preload("Staff").
-		Preload("Items.Variant.Product").
-		Find(&orders).Error
-	return orders, err
+	query := r.db.
+		Preload("Customer", withTrashed).
+		Preload("Staff", withTrashed).
+		Preload("Items.Variant.Product", withTrashed)
+	meta, err := listquery.Find(query, orderListSpec, p, &orders)
+	return orders, meta, err
 }
 
 // Lấy chi tiết 1 order
-func GetOrderDetail(id uint) (*models.Order, error) {
+func (r *orderRepository) GetOrderDetail(id uint) (*models.Order, error) {
 	var order models.Order
-	err := configs.DB.
-		Preload("Customer").
-		Preload("Staff").
-		Preload("Items.Variant.Product").
+	err := r.db.
+		Preload("Customer", withTrashed).
+		Preload("Staff", withTrashed).
+		Preload("Items.Variant.Product", withTrashed).
+		Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Preload("History.Actor", withTrashed).
 		First(&order, id).Error
 	if err != nil {
 		return nil, err
@@ -31,12 +68,100 @@ func GetOrderDetail(id uint) (*models.Order, error) {
 	return &order, nil
 }
 
-// Cập nhật trạng thái order
-func UpdateOrderStatus(id uint, status string, staffID *uint) error {
-	return configs.DB.Model(&models.Order{}).
-		Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"status":   status,
-			"staff_id": staffID,
-		}).Error
+// orderStatusHooks chạy trong cùng transaction khi order chuyển sang status tương ứng
+var orderStatusHooks = map[string]func(tx *gorm.DB, order *models.Order) error{
+	service.OrderCancelled: restockCancelledOrder,
+	service.OrderCompleted: stampOrderCompleted,
+}
+
+// Cập nhật trạng thái order theo state machine (service.ValidateOrderTransition)
+// và ghi OrderStatusHistory với người thực hiện actor; actor thành staff phụ trách order.
+func (r *orderRepository) UpdateOrderStatus(id uint, status string, actor *service.Claims, note string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+			return err
+		}
+
+		// Giữ nguyên status: chỉ nhận order về cho actor
+		if status != order.Status {
+			if err := r.ChangeOrderStatus(tx, &order, status, actor, note); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Order{}).Where("id = ?", id).Update("staff_id", actor.UserID).Error
+	})
+}
+
+// ChangeOrderStatus kiểm tra transition, chạy hook, lưu status mới và ghi history.
+// Caller phải khóa order (SELECT ... FOR UPDATE) trong transaction tx.
+func (r *orderRepository) ChangeOrderStatus(tx *gorm.DB, order *models.Order, status string, actor *service.Claims, note string) error {
+	if err := service.ValidateOrderTransition(order.Status, status); err != nil {
+		return err
+	}
+	if hook, ok := orderStatusHooks[status]; ok {
+		if err := hook(tx, order); err != nil {
+			return err
+		}
+	}
+	if err := CreateOrderStatusHistory(tx, order.ID, order.Status, status, actor, note); err != nil {
+		return err
+	}
+	if err := tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("status", status).Error; err != nil {
+		return err
+	}
+	order.Status = status
+	return nil
+}
+
+// CreateOrderStatusHistory ghi 1 dòng timeline cho order (dùng trong transaction của caller)
+func CreateOrderStatusHistory(tx *gorm.DB, orderID uint, oldStatus, newStatus string, actor *service.Claims, note string) error {
+	history := models.OrderStatusHistory{
+		OrderID:   orderID,
+		OldStatus: oldStatus,
+		NewStatus: newStatus,
+		Note:      note,
+	}
+	if actor != nil {
+		actorID := actor.UserID
+		history.ActorID = &actorID
+		history.ActorRole = actor.Role
+	}
+	return tx.Omit("Actor").Create(&history).Error
+}
+
+// Hủy đơn: hoàn lại tồn kho và ghi InventoryLog "return"
+func restockCancelledOrder(tx *gorm.DB, order *models.Order) error {
+	var items []models.OrderItem
+	if err := tx.Where("order_id = ?", order.ID).Order("variant_id").Find(&items).Error; err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := tx.Model(&models.ProductVariant{}).
+			Where("id = ?", item.VariantID).
+			Updates(map[string]interface{}{
+				"stock":   gorm.Expr("stock + ?", item.Quantity),
+				"version": gorm.Expr("version + 1"),
+			}).Error; err != nil {
+			return err
+		}
+		log := models.InventoryLog{
+			VariantID:  item.VariantID,
+			ChangeType: "return",
+			Quantity:   item.Quantity,
+			Note:       "Cancelled order #" + strconv.Itoa(int(order.ID)),
+		}
+		if err := tx.Omit("Variant").Create(&log).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Hoàn tất đơn: ghi lại thời điểm hoàn tất
+func stampOrderCompleted(tx *gorm.DB, order *models.Order) error {
+	now := time.Now()
+	order.CompletedAt = &now
+	return tx.Model(&models.Order{}).Where("id = ?", order.ID).Update("completed_at", now).Error
 }
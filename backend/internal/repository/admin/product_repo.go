@@ -1,105 +1,273 @@
 package admin
 
 import (
-	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/listquery"
 	"backend/internal/models"
-	"errors"
+	"backend/internal/service"
+
+	"gorm.io/gorm"
 )
 
+// ProductRepository: truy cập dữ liệu product + variant cho trang admin
+type ProductRepository interface {
+	GetAllProducts(p listquery.Params) ([]models.Product, *listquery.Meta, error)
+	GetProductDetail(id uint) (*models.Product, error)
+	CreateProduct(p *models.Product) (*models.Product, error)
+	UpdateProduct(id uint, newData *models.Product, version uint) (*models.Product, error)
+	SetProductPublished(id uint, published bool) (*models.Product, error)
+	DeleteProduct(id uint) error
+	GetTrashedProducts() ([]models.Product, error)
+	RestoreProduct(id uint) error
+	PurgeProduct(id uint) error
+	GetVariantsByProduct(productID uint) ([]models.ProductVariant, error)
+	GetAllVariants() ([]models.ProductVariant, error)
+	GetVariantDetail(productID, id uint) (*models.ProductVariant, error)
+	CreateVariant(v *models.ProductVariant) (*models.ProductVariant, error)
+	UpdateVariant(productID, id uint, newData *models.ProductVariant, version uint) (*models.ProductVariant, error)
+	DeleteVariant(productID, id uint) error
+}
+
+type productRepository struct {
+	db *gorm.DB
+}
+
+func NewProductRepository(db *gorm.DB) ProductRepository {
+	return &productRepository{db: db}
+}
+
 // PRODUCTS
-func GetAllProducts() ([]models.Product, error) {
+
+// productListSpec: cột được sort / filter ở GET /api/admin/products
+var productListSpec = listquery.Spec{
+	Sorts:       map[string]string{"id": "id", "name": "name", "price": "price", "created_at": "created_at"},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"category_id": "category_id", "is_published": "is_published", "created": "created_at"},
+}
+
+func (r *productRepository) GetAllProducts(p listquery.Params) ([]models.Product, *listquery.Meta, error) {
 	var products []models.Product
-	err := configs.DB.Preload("Variants").Find(&products).Error
-	return products, err
+	meta, err := listquery.Find(r.db.Preload("Variants"), productListSpec, p, &products)
+	return products, meta, err
 }
 
-func GetProductDetail(id uint) (*models.Product, error) {
+func (r *productRepository) GetProductDetail(id uint) (*models.Product, error) {
 	var product models.Product
-	err := configs.DB.Preload("Variants").First(&product, id).Error
+	err := r.db.Preload("Variants").First(&product, id).Error
 	return &product, err
 }
 
-func CreateProduct(p *models.Product) (*models.Product, error) {
-	if err := configs.DB.Create(p).Error; err != nil {
+func (r *productRepository) CreateProduct(p *models.Product) (*models.Product, error) {
+	if err := r.db.Create(p).Error; err != nil {
 		return nil, err
 	}
 	return p, nil
 }
 
-func UpdateProduct(id uint, newData *models.Product) (*models.Product, error) {
-	var p models.Product
-	if err := configs.DB.First(&p, id).Error; err != nil {
+// UpdateProduct chỉ ghi khi version còn khớp (If-Match), ngược lại trả ErrVersionConflict
+func (r *productRepository) UpdateProduct(id uint, newData *models.Product, version uint) (*models.Product, error) {
+	if err := updateVersioned(r.db, &models.Product{}, id, version, map[string]interface{}{
+		"name":        newData.Name,
+		"description": newData.Description,
+		"category_id": newData.CategoryID,
+		"image":       newData.Image,
+		"price":       newData.Price,
+		"discount":    newData.Discount,
+	}); err != nil {
 		return nil, err
 	}
-	p.Name = newData.Name
-	p.Description = newData.Description
-	p.CategoryID = newData.CategoryID
-	p.Image = newData.Image
-	p.Price = newData.Price
-	p.Discount = newData.Discount
-	if err := configs.DB.Save(&p).Error; err != nil {
+	var p models.Product
+	if err := r.db.First(&p, id).Error; err != nil {
 		return nil, err
 	}
 	return &p, nil
 }
 
-func DeleteProduct(id uint) error {
-	return configs.DB.Delete(&models.Product{}, id).Error
+// SetProductPublished ẩn / hiện sản phẩm trên storefront
+func (r *productRepository) SetProductPublished(id uint, published bool) (*models.Product, error) {
+	var p models.Product
+	if err := r.db.First(&p, id).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Model(&p).Updates(map[string]interface{}{
+		"is_published": published,
+		"version":      gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return nil, err
+	}
+	return r.GetProductDetail(id)
+}
+
+// variantIDsOf: subquery id các variant của product
+func (r *productRepository) variantIDsOf(db *gorm.DB, productID uint) *gorm.DB {
+	return db.Model(&models.ProductVariant{}).Select("id").Where("product_id = ?", productID)
+}
+
+// DeleteProduct soft-delete sản phẩm (vào thùng rác), chặn nếu còn đơn đang xử lý
+func (r *productRepository) DeleteProduct(id uint) error {
+	liveOrders := r.db.Model(&models.Order{}).
+		Where("status IN ?", service.LiveOrderStatuses).
+		Where("id IN (?)", r.db.Model(&models.OrderItem{}).Select("order_id").
+			Where("variant_id IN (?)", r.variantIDsOf(r.db, id)))
+	if err := checkDependents("product", id, dependentCheck{"orders", liveOrders}); err != nil {
+		return err
+	}
+	res := r.db.Delete(&models.Product{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetTrashedProducts: danh sách sản phẩm đã soft-delete
+func (r *productRepository) GetTrashedProducts() ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Scopes(onlyTrashed).Preload("Category", withTrashed).Order("deleted_at desc").Find(&products).Error
+	return products, err
+}
+
+// RestoreProduct khôi phục sản phẩm trong thùng rác; category còn ở thùng rác -> ErrParentTrashed,
+// vì sản phẩm sống dưới category đã xóa sẽ bị catalog ẩn đi mà admin không biết
+func (r *productRepository) RestoreProduct(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Scopes(onlyTrashed).Select("id", "category_id").First(&product, id).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Scopes(onlyTrashed).Model(&models.Category{}).Where("id = ?", product.CategoryID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrParentTrashed.WithDetails(map[string]interface{}{"resource": "category", "id": product.CategoryID})
+		}
+		return restoreTrashed(tx, &models.Product{}, id)
+	})
+}
+
+// PurgeProduct xóa vĩnh viễn sản phẩm trong thùng rác; variant và cart item đi theo (ON DELETE CASCADE).
+// Bị chặn nếu variant còn nằm trong đơn hàng, phiếu nhập hoặc inventory log.
+func (r *productRepository) PurgeProduct(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Scopes(onlyTrashed).Model(&models.Product{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		variantIDs := r.variantIDsOf(tx, id)
+		if err := checkDependents("product", id,
+			dependentCheck{"order_items", tx.Model(&models.OrderItem{}).Where("variant_id IN (?)", variantIDs)},
+			dependentCheck{"purchases", tx.Model(&models.Purchase{}).Where("variant_id IN (?)", variantIDs)},
+			dependentCheck{"inventory_logs", tx.Model(&models.InventoryLog{}).Where("variant_id IN (?)", variantIDs)},
+		); err != nil {
+			return err
+		}
+		return purgeTrashed(tx, &models.Product{}, id)
+	})
 }
 
 // VARIANTS
-func GetVariantsByProduct(productID uint) ([]models.ProductVariant, error) {
+func (r *productRepository) GetVariantsByProduct(productID uint) ([]models.ProductVariant, error) {
 	var variants []models.ProductVariant
-	err := configs.DB.
+	err := r.db.
 		Where("product_id = ?", productID).
 		Preload("Product").
 		Find(&variants).Error
 	return variants, err
 }
 
-func GetAllVariants() ([]models.ProductVariant, error) {
+func (r *productRepository) GetAllVariants() ([]models.ProductVariant, error) {
 	var variants []models.ProductVariant
-	err := configs.DB.Preload("Product").Find(&variants).Error
+	// Bỏ variant của sản phẩm đang ở thùng rác (subquery trên Product tự lọc deleted_at)
+	err := r.db.Preload("Product").
+		Where("product_id IN (?)", r.db.Model(&models.Product{}).Select("id")).
+		Find(&variants).Error
 	return variants, err
 }
-func CreateVariant(v *models.ProductVariant) (*models.ProductVariant, error) {
+func (r *productRepository) GetVariantDetail(productID, id uint) (*models.ProductVariant, error) {
+	var v models.ProductVariant
+	if err := r.db.Where("product_id = ?", productID).First(&v, id).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ErrSKUTaken: SKU đã được variant khác dùng
+var ErrSKUTaken = apperr.Conflict("SKU already exists").WithCode("sku_taken")
+
+func (r *productRepository) CreateVariant(v *models.ProductVariant) (*models.ProductVariant, error) {
 	// Kiểm tra trùng SKU
 	var count int64
-	configs.DB.Model(&models.ProductVariant{}).Where("sku = ?", v.SKU).Count(&count)
+	if err := r.db.Model(&models.ProductVariant{}).Where("sku = ?", v.SKU).Count(&count).Error; err != nil {
+		return nil, err
+	}
 	if count > 0 {
-		return nil, errors.New("SKU already exists")
+		return nil, ErrSKUTaken
 	}
-	if err := configs.DB.Create(v).Error; err != nil {
+	if err := r.db.Create(v).Error; err != nil {
 		return nil, err
 	}
 	return v, nil
 }
 
-func UpdateVariant(id uint, newData *models.ProductVariant) (*models.ProductVariant, error) {
-	var v models.ProductVariant
-	if err := configs.DB.First(&v, id).Error; err != nil {
-		return nil, err
-	}
+// UpdateVariant chỉ ghi khi version còn khớp; mọi thay đổi tồn kho (bán, nhập, hủy đơn)
+// cũng tăng version nên form sửa cũ không thể ghi đè Stock mới
+func (r *productRepository) UpdateVariant(productID, id uint, newData *models.ProductVariant, version uint) (*models.ProductVariant, error) {
 	// Kiểm tra trùng SKU với bản ghi khác
 	var count int64
-	configs.DB.Model(&models.ProductVariant{}).
+	if err := r.db.Model(&models.ProductVariant{}).
 		Where("sku = ? AND id <> ?", newData.SKU, id).
-		Count(&count)
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
 	if count > 0 {
-		return nil, errors.New("SKU already exists")
-	}
-	v.Size = newData.Size
-	v.Color = newData.Color
-	v.Price = newData.Price
-	v.Stock = newData.Stock
-	v.SKU = newData.SKU
-	v.Image = newData.Image
-	if err := configs.DB.Save(&v).Error; err != nil {
+		return nil, ErrSKUTaken
+	}
+	// Variant phải thuộc product trên URL, khác product -> 404
+	scoped := r.db.Where("product_id = ?", productID)
+	if err := updateVersioned(scoped, &models.ProductVariant{}, id, version, map[string]interface{}{
+		"size":  newData.Size,
+		"color": newData.Color,
+		"price": newData.Price,
+		"stock": newData.Stock,
+		"sku":   newData.SKU,
+		"image": newData.Image,
+	}); err != nil {
+		return nil, err
+	}
+	var v models.ProductVariant
+	if err := r.db.First(&v, id).Error; err != nil {
 		return nil, err
 	}
 	return &v, nil
 }
 
-func DeleteVariant(id uint) error {
-	return configs.DB.Delete(&models.ProductVariant{}, id).Error
+// DeleteVariant xóa hẳn variant (variant không có soft delete); cart item đi theo,
+// còn đơn hàng / phiếu nhập / inventory log tham chiếu tới thì bị chặn.
+// Variant phải thuộc product trên URL, khác product -> 404
+func (r *productRepository) DeleteVariant(productID, id uint) error {
+	scoped := r.db.Where("product_id = ?", productID).Session(&gorm.Session{})
+	if err := scoped.Select("id").First(&models.ProductVariant{}, id).Error; err != nil {
+		return err
+	}
+	if err := checkDependents("variant", id,
+		dependentCheck{"order_items", r.db.Model(&models.OrderItem{}).Where("variant_id = ?", id)},
+		dependentCheck{"purchases", r.db.Model(&models.Purchase{}).Where("variant_id = ?", id)},
+		dependentCheck{"inventory_logs", r.db.Model(&models.InventoryLog{}).Where("variant_id = ?", id)},
+	); err != nil {
+		return err
+	}
+	res := scoped.Delete(&models.ProductVariant{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
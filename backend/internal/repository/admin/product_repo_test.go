@@ -0,0 +1,68 @@
+package admin
+
+import (
+	"backend/internal/models"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// Xóa variant qua URL của product khác -> 404, variant vẫn còn
+func TestDeleteVariantScopedToProduct(t *testing.T) {
+	f := newFixture(t, 0)
+	repo := NewProductRepository(f.db)
+	v := f.variants[0]
+	other := models.Product{Name: "Quần", CategoryID: 1, Price: 100}
+	mustCreate(t, f.db, &other)
+
+	if err := repo.DeleteVariant(other.ID, v.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("err = %v, want ErrRecordNotFound", err)
+	}
+	if _, err := repo.GetVariantDetail(v.ProductID, v.ID); err != nil {
+		t.Fatalf("variant deleted through another product: %v", err)
+	}
+
+	if err := repo.DeleteVariant(v.ProductID, v.ID); err != nil {
+		t.Fatalf("DeleteVariant: %v", err)
+	}
+	if _, err := repo.GetVariantDetail(v.ProductID, v.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("err = %v, want ErrRecordNotFound after delete", err)
+	}
+}
+
+// Sản phẩm không được khôi phục khi category vẫn ở thùng rác
+func TestRestoreProductRequiresLiveCategory(t *testing.T) {
+	f := newFixture(t, 0)
+	repo := NewProductRepository(f.db)
+	var product models.Product
+	if err := f.db.First(&product, f.variants[0].ProductID).Error; err != nil {
+		t.Fatalf("load product: %v", err)
+	}
+	category := models.Category{ID: product.CategoryID}
+	mustDelete := func(value interface{}) {
+		t.Helper()
+		if err := f.db.Delete(value).Error; err != nil {
+			t.Fatalf("soft delete %T: %v", value, err)
+		}
+	}
+	mustDelete(&product)
+	mustDelete(&category)
+
+	if err := repo.RestoreProduct(product.ID); !errors.Is(err, ErrParentTrashed) {
+		t.Fatalf("err = %v, want ErrParentTrashed", err)
+	}
+	if _, err := repo.GetProductDetail(product.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("product restored under trashed category: %v", err)
+	}
+
+	if err := NewCategoryRepository(f.db).RestoreCategory(category.ID); err != nil {
+		t.Fatalf("RestoreCategory: %v", err)
+	}
+	if err := repo.RestoreProduct(product.ID); err != nil {
+		t.Fatalf("RestoreProduct: %v", err)
+	}
+	if err := repo.RestoreProduct(product.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("restore twice: err = %v, want ErrRecordNotFound", err)
+	}
+}
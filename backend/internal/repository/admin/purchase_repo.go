@@ -1,167 +1,172 @@
 package admin
 
 import (
-	"backend/configs"
+	"backend/internal/listquery"
 	"backend/internal/models"
-	"errors"
-    "strconv"
-	
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// PurchaseRepository: truy cập dữ liệu purchase cho trang admin
+type PurchaseRepository interface {
+	GetAllPurchases(p listquery.Params) ([]models.Purchase, *listquery.Meta, error)
+	GetPurchasesBySupplier(supplierID uint, p listquery.Params) ([]models.Purchase, *listquery.Meta, error)
+	CreatePurchase(p *models.Purchase) (*models.Purchase, error)
+	UpdatePurchase(id uint, newData *models.Purchase) (*models.Purchase, error)
+	DeletePurchase(id uint) error
+}
+
+type purchaseRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseRepository(db *gorm.DB) PurchaseRepository {
+	return &purchaseRepository{db: db}
+}
+
+// purchaseListSpec: cột được sort / filter ở GET /api/admin/purchases và /api/admin/suppliers/{id}/purchases
+var purchaseListSpec = listquery.Spec{
+	Sorts:       map[string]string{"id": "id", "created_at": "created_at", "quantity": "quantity", "cost_price": "cost_price"},
+	DefaultSort: "-created_at",
+	Filters: map[string]string{
+		"supplier_id": "supplier_id",
+		"staff_id":    "staff_id",
+		"variant_id":  "variant_id",
+		"created":     "created_at",
+	},
+}
+
 // Get all purchases (global)
-func GetAllPurchases() ([]models.Purchase, error) {
+func (r *purchaseRepository) GetAllPurchases(p listquery.Params) ([]models.Purchase, *listquery.Meta, error) {
 	var purchases []models.Purchase
-	err := configs.DB.Preload("Supplier").Preload("Staff").Preload("Variant").Find(&purchases).Error
-	return purchases, err
+	query := r.db.Preload("Supplier", withTrashed).Preload("Staff", withTrashed).Preload("Variant")
+	meta, err := listquery.Find(query, purchaseListSpec, p, &purchases)
+	return purchases, meta, err
 }
 
 // Get purchases by supplier
-func GetPurchasesBySupplier(supplierID uint) ([]models.Purchase, error) {
+func (r *purchaseRepository) GetPurchasesBySupplier(supplierID uint, p listquery.Params) ([]models.Purchase, *listquery.Meta, error) {
 	var purchases []models.Purchase
-	err := configs.DB.Preload("Variant").Preload("Staff").Where("supplier_id = ?", supplierID).Find(&purchases).Error
-	return purchases, err
+	query := r.db.Preload("Variant").Preload("Staff", withTrashed).Where("supplier_id = ?", supplierID)
+	meta, err := listquery.Find(query, purchaseListSpec, p, &purchases)
+	return purchases, meta, err
 }
 
-func CreatePurchase(p *models.Purchase) (*models.Purchase, error) {
-    // KHÔNG set hoặc truyền p.Total khi tạo purchase
-    if err := configs.DB.Omit("Total").Create(p).Error; err != nil {
-        return nil, err
-    }
-
-    // Lấy variant
-    var variant models.ProductVariant
-    if err := configs.DB.First(&variant, p.VariantID).Error; err != nil {
-        return nil, errors.New("variant not found")
-    }
-
-    // Cập nhật stock
-    variant.Stock += p.Quantity
-    if err := configs.DB.Save(&variant).Error; err != nil {
-        return nil, err
-    }
-
-    // Tạo InventoryLog
-    log := models.InventoryLog{
-        VariantID:  p.VariantID,
-        ChangeType: "import",
-        Quantity:   p.Quantity,
-        Note:       "Auto created from purchase #" + strconv.Itoa(int(p.ID)),
-    }
-    if err := configs.DB.Create(&log).Error; err != nil {
-        return nil, err
-    }
-
-    return p, nil
+// CreatePurchase tạo phiếu nhập, cộng tồn kho và ghi InventoryLog "import" trong 1 transaction.
+// Variant bị khóa (SELECT ... FOR UPDATE) để các thao tác tồn kho đồng thời không ghi đè nhau.
+func (r *purchaseRepository) CreatePurchase(p *models.Purchase) (*models.Purchase, error) {
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		variant, err := lockVariant(tx, p.VariantID)
+		if err != nil {
+			return err
+		}
+
+		// KHÔNG set hoặc truyền p.Total khi tạo purchase
+		if err := tx.Omit("Total", "Supplier", "Staff", "Variant").Create(p).Error; err != nil {
+			return err
+		}
+
+		// Cập nhật stock
+		if err := addVariantStock(tx, variant, p.Quantity); err != nil {
+			return err
+		}
+
+		// Tạo InventoryLog
+		return createInventoryLog(tx, p.VariantID, "import", p.Quantity,
+			"Auto created from purchase #"+strconv.Itoa(int(p.ID)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
-
-func UpdatePurchase(id uint, newData *models.Purchase) (*models.Purchase, error) {
-    var p models.Purchase
-    if err := configs.DB.First(&p, id).Error; err != nil {
-        return nil, err
-    }
-
-    // Lấy variant cũ
-    var variant models.ProductVariant
-    if err := configs.DB.First(&variant, p.VariantID).Error; err != nil {
-        return nil, errors.New("variant not found")
-    }
-
-    oldQuantity := p.Quantity
-
-    // Nếu variantID thay đổi, rollback stock của variant cũ
-    if p.VariantID != newData.VariantID {
-        variant.Stock -= oldQuantity
-        if err := configs.DB.Save(&variant).Error; err != nil {
-            return nil, err
-        }
-
-        // Ghi log rollback
-        logRollback := models.InventoryLog{
-            VariantID:  variant.ID,
-            ChangeType: "adjust",
-            Quantity:   variant.Stock,
-            Note:       "Rollback stock from purchase update #" + strconv.Itoa(int(p.ID)),
-        }
-        configs.DB.Create(&logRollback)
-
-        // Update variant mới
-        var newVariant models.ProductVariant
-        if err := configs.DB.First(&newVariant, newData.VariantID).Error; err != nil {
-            return nil, errors.New("new variant not found")
-        }
-        newVariant.Stock += newData.Quantity
-        if err := configs.DB.Save(&newVariant).Error; err != nil {
-            return nil, err
-        }
-
-        // Log update
-        logNew := models.InventoryLog{
-            VariantID:  newVariant.ID,
-            ChangeType: "import",
-            Quantity:   newData.Quantity,
-            Note:       "Update purchase #" + strconv.Itoa(int(p.ID)),
-        }
-        configs.DB.Create(&logNew)
-    } else {
-        // Variant giữ nguyên, chỉ tính chênh lệch
-        diff := int(newData.Quantity) - int(oldQuantity)
-        variant.Stock += diff
-        if err := configs.DB.Save(&variant).Error; err != nil {
-            return nil, err
-        }
-
-        log := models.InventoryLog{
-            VariantID:  variant.ID,
-            ChangeType: "adjust",
-            Quantity:   diff,
-            Note:       "Update purchase #" + strconv.Itoa(int(p.ID)),
-        }
-        configs.DB.Create(&log)
-    }
-
-    // Update các field khác
-    p.VariantID = newData.VariantID
-    p.Quantity = newData.Quantity
-    p.CostPrice = newData.CostPrice
-    p.StaffID = newData.StaffID
-    if err := configs.DB.Omit("Total").Save(&p).Error; err != nil {
-        return nil, err
-    }
-
-    return &p, nil
+// UpdatePurchase sửa phiếu nhập và điều chỉnh tồn kho tương ứng trong 1 transaction.
+// Chênh lệch tồn kho được ghi log "purchase_correction" (quantity có dấu), khác với "adjust"
+// (quantity là tồn kho mới) của log tạo thủ công.
+// Hàng của phiếu cũ đã bán bớt khiến tồn kho âm -> ErrInsufficientStock, không sửa gì.
+func (r *purchaseRepository) UpdatePurchase(id uint, newData *models.Purchase) (*models.Purchase, error) {
+	var p models.Purchase
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, id).Error; err != nil {
+			return err
+		}
+
+		// Khóa variant cũ (và variant mới nếu đổi) theo thứ tự id để tránh deadlock
+		variants, err := lockVariants(tx, p.VariantID, newData.VariantID)
+		if err != nil {
+			return err
+		}
+		variant := variants[p.VariantID]
+		note := "Update purchase #" + strconv.Itoa(int(p.ID))
+
+		if p.VariantID != newData.VariantID {
+			// Đổi variant: trả lại số lượng cũ của variant cũ, nhập số lượng mới vào variant mới
+			if err := addVariantStock(tx, variant, -p.Quantity); err != nil {
+				return err
+			}
+			if err := createInventoryLog(tx, variant.ID, "purchase_correction", -p.Quantity,
+				"Rollback stock from purchase update #"+strconv.Itoa(int(p.ID))); err != nil {
+				return err
+			}
+
+			newVariant := variants[newData.VariantID]
+			if err := addVariantStock(tx, newVariant, newData.Quantity); err != nil {
+				return err
+			}
+			if err := createInventoryLog(tx, newVariant.ID, "import", newData.Quantity, note); err != nil {
+				return err
+			}
+		} else if diff := newData.Quantity - p.Quantity; diff != 0 {
+			// Variant giữ nguyên, chỉ tính chênh lệch
+			if err := addVariantStock(tx, variant, diff); err != nil {
+				return err
+			}
+			if err := createInventoryLog(tx, variant.ID, "purchase_correction", diff, note); err != nil {
+				return err
+			}
+		}
+
+		// Update các field khác; staff giữ nguyên người tạo phiếu
+		p.VariantID = newData.VariantID
+		p.Quantity = newData.Quantity
+		p.CostPrice = newData.CostPrice
+		return tx.Omit("Total", "Supplier", "Staff", "Variant").Save(&p).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
 }
 
-func DeletePurchase(id uint) error {
-    var p models.Purchase
-    if err := configs.DB.First(&p, id).Error; err != nil {
-        return err
-    }
-
-    // Lấy variant
-    var variant models.ProductVariant
-    if err := configs.DB.First(&variant, p.VariantID).Error; err != nil {
-        return errors.New("variant not found")
-    }
-
-    // Trừ stock
-    variant.Stock -= p.Quantity
-    if variant.Stock < 0 {
-        variant.Stock = 0 // tránh âm
-    }
-    if err := configs.DB.Save(&variant).Error; err != nil {
-        return err
-    }
-
-    // Ghi log
-    log := models.InventoryLog{
-        VariantID:  variant.ID,
-        ChangeType: "adjust",
-        Quantity:   -p.Quantity,
-        Note:       "Deleted purchase #" + strconv.Itoa(int(p.ID)),
-    }
-    configs.DB.Create(&log)
-
-    // Xóa purchase
-    return configs.DB.Delete(&models.Purchase{}, id).Error
+// DeletePurchase xóa phiếu nhập và trừ lại tồn kho trong 1 transaction.
+// Hàng của phiếu đã bán bớt (tồn kho không đủ để trừ) -> ErrInsufficientStock.
+func (r *purchaseRepository) DeletePurchase(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var p models.Purchase
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&p, id).Error; err != nil {
+			return err
+		}
+
+		variant, err := lockVariant(tx, p.VariantID)
+		if err != nil {
+			return err
+		}
+
+		// Trừ stock
+		if err := addVariantStock(tx, variant, -p.Quantity); err != nil {
+			return err
+		}
+
+		// Ghi log
+		if err := createInventoryLog(tx, variant.ID, "purchase_correction", -p.Quantity,
+			"Deleted purchase #"+strconv.Itoa(int(p.ID))); err != nil {
+			return err
+		}
+
+		// Xóa purchase
+		return tx.Delete(&models.Purchase{}, id).Error
+	})
 }
@@ -0,0 +1,202 @@
+package admin
+
+import (
+	"backend/internal/models"
+	"backend/internal/testdb"
+	"errors"
+	"strconv"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// fixture: 1 staff, 1 supplier, 1 product với 2 variant có tồn kho ban đầu stock
+type fixture struct {
+	db       *gorm.DB
+	staff    models.User
+	supplier models.Supplier
+	variants [2]models.ProductVariant
+}
+
+func newFixture(t *testing.T, stock int) *fixture {
+	t.Helper()
+	f := &fixture{db: testdb.Open(t)}
+	f.staff = models.User{Username: "staff", Email: "staff@example.com", PasswordHash: "x", Role: "staff"}
+	f.supplier = models.Supplier{Name: "Supplier"}
+	category := models.Category{Name: "Áo", GroupName: "Đồ nam"}
+	mustCreate(t, f.db, &f.staff, &f.supplier, &category)
+	product := models.Product{Name: "Áo thun", CategoryID: category.ID, Price: 100}
+	mustCreate(t, f.db, &product)
+	for i := range f.variants {
+		f.variants[i] = models.ProductVariant{ProductID: product.ID, SKU: "SKU-" + strconv.Itoa(i), Stock: stock}
+		mustCreate(t, f.db, &f.variants[i])
+	}
+	return f
+}
+
+func mustCreate(t *testing.T, db *gorm.DB, values ...interface{}) {
+	t.Helper()
+	for _, v := range values {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("create %T: %v", v, err)
+		}
+	}
+}
+
+func (f *fixture) stock(t *testing.T, variantID uint) int {
+	t.Helper()
+	var v models.ProductVariant
+	if err := f.db.First(&v, variantID).Error; err != nil {
+		t.Fatalf("load variant %d: %v", variantID, err)
+	}
+	return v.Stock
+}
+
+// replay: tồn kho có được khi chạy lại inventory_logs (theo thứ tự id) từ tồn kho ban đầu.
+// adjust đặt tồn kho mới, purchase_correction cộng chênh lệch có dấu.
+func (f *fixture) replay(t *testing.T, variantID uint, initial int) int {
+	t.Helper()
+	var logs []models.InventoryLog
+	if err := f.db.Where("variant_id = ?", variantID).Order("id").Find(&logs).Error; err != nil {
+		t.Fatalf("load logs: %v", err)
+	}
+	stock := initial
+	for _, l := range logs {
+		switch l.ChangeType {
+		case "import", "return", "purchase_correction":
+			stock += l.Quantity
+		case "sale":
+			stock -= l.Quantity
+		case "adjust":
+			stock = l.Quantity
+		default:
+			t.Fatalf("unknown change type %q", l.ChangeType)
+		}
+	}
+	return stock
+}
+
+// assertConsistent: tồn kho hiện tại = kết quả chạy lại sổ kho, với mọi variant
+func (f *fixture) assertConsistent(t *testing.T, initial int) {
+	t.Helper()
+	for _, v := range f.variants {
+		if got, want := f.stock(t, v.ID), f.replay(t, v.ID, initial); got != want {
+			t.Errorf("variant %d: stock = %d, replayed ledger = %d", v.ID, got, want)
+		}
+	}
+}
+
+func (f *fixture) purchase(variantID uint, quantity int) *models.Purchase {
+	return &models.Purchase{SupplierID: f.supplier.ID, StaffID: f.staff.ID, VariantID: variantID, Quantity: quantity, CostPrice: 50}
+}
+
+func TestCreatePurchaseAddsStockAndLogsImport(t *testing.T) {
+	f := newFixture(t, 2)
+	repo := NewPurchaseRepository(f.db)
+
+	p, err := repo.CreatePurchase(f.purchase(f.variants[0].ID, 3))
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	if got := f.stock(t, f.variants[0].ID); got != 5 {
+		t.Errorf("stock = %d, want 5", got)
+	}
+	var log models.InventoryLog
+	if err := f.db.Where("variant_id = ?", f.variants[0].ID).First(&log).Error; err != nil {
+		t.Fatalf("load log: %v", err)
+	}
+	if log.ChangeType != "import" || log.Quantity != 3 || log.Note != "Auto created from purchase #"+strconv.Itoa(int(p.ID)) {
+		t.Errorf("log = %+v", log)
+	}
+	f.assertConsistent(t, 2)
+}
+
+func TestUpdatePurchaseKeepsLedgerConsistent(t *testing.T) {
+	f := newFixture(t, 2)
+	repo := NewPurchaseRepository(f.db)
+
+	p, err := repo.CreatePurchase(f.purchase(f.variants[0].ID, 3))
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+
+	// Cùng variant: chỉ ghi chênh lệch
+	if _, err := repo.UpdatePurchase(p.ID, f.purchase(f.variants[0].ID, 5)); err != nil {
+		t.Fatalf("UpdatePurchase quantity: %v", err)
+	}
+	if got := f.stock(t, f.variants[0].ID); got != 7 {
+		t.Errorf("stock after quantity change = %d, want 7", got)
+	}
+
+	// Đổi variant: variant cũ bị trừ đúng số lượng cũ, variant mới được cộng số lượng mới
+	if _, err := repo.UpdatePurchase(p.ID, f.purchase(f.variants[1].ID, 4)); err != nil {
+		t.Fatalf("UpdatePurchase variant: %v", err)
+	}
+	if got := f.stock(t, f.variants[0].ID); got != 2 {
+		t.Errorf("old variant stock = %d, want 2", got)
+	}
+	if got := f.stock(t, f.variants[1].ID); got != 6 {
+		t.Errorf("new variant stock = %d, want 6", got)
+	}
+	var rollback models.InventoryLog
+	f.db.Where("variant_id = ? AND change_type = ?", f.variants[0].ID, "purchase_correction").Order("id desc").First(&rollback)
+	if rollback.Quantity != -5 {
+		t.Errorf("rollback log quantity = %d, want -5", rollback.Quantity)
+	}
+
+	// Kiểm kê thủ công (adjust = tồn kho mới) xen giữa các log của phiếu nhập
+	inventory := NewInventoryRepository(f.db)
+	if _, err := inventory.CreateInventoryLog(&models.InventoryLog{VariantID: f.variants[1].ID, ChangeType: "adjust", Quantity: 8}); err != nil {
+		t.Fatalf("adjust: %v", err)
+	}
+
+	if err := repo.DeletePurchase(p.ID); err != nil {
+		t.Fatalf("DeletePurchase: %v", err)
+	}
+	if got := f.stock(t, f.variants[1].ID); got != 4 {
+		t.Errorf("stock after delete = %d, want 4", got)
+	}
+	f.assertConsistent(t, 2)
+}
+
+// Hàng của phiếu nhập đã bán hết thì không được giảm / xóa phiếu làm tồn kho âm
+func TestPurchaseChangesRejectNegativeStock(t *testing.T) {
+	f := newFixture(t, 6)
+	purchases := NewPurchaseRepository(f.db)
+	inventory := NewInventoryRepository(f.db)
+	variantID := f.variants[0].ID
+
+	p, err := purchases.CreatePurchase(f.purchase(variantID, 3))
+	if err != nil {
+		t.Fatalf("CreatePurchase: %v", err)
+	}
+	if _, err := inventory.CreateInventoryLog(&models.InventoryLog{VariantID: variantID, ChangeType: "sale", Quantity: 9}); err != nil {
+		t.Fatalf("sale: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		run  func() error
+	}{
+		{"lower quantity", func() error {
+			_, err := purchases.UpdatePurchase(p.ID, f.purchase(variantID, 1))
+			return err
+		}},
+		{"move to other variant", func() error {
+			_, err := purchases.UpdatePurchase(p.ID, f.purchase(f.variants[1].ID, 3))
+			return err
+		}},
+		{"delete", func() error { return purchases.DeletePurchase(p.ID) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.run(); !errors.Is(err, ErrInsufficientStock) {
+				t.Fatalf("err = %v, want ErrInsufficientStock", err)
+			}
+			if got := f.stock(t, variantID); got != 0 {
+				t.Errorf("stock = %d, want 0", got)
+			}
+			f.assertConsistent(t, 6)
+		})
+	}
+}
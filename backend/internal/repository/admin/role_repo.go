@@ -0,0 +1,214 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"backend/internal/service"
+
+	"gorm.io/gorm"
+)
+
+// RoleRepository: truy cập dữ liệu role + permission cho trang admin
+type RoleRepository interface {
+	SeedRolesAndPermissions() error
+	GetAllRoles() ([]models.Role, error)
+	GetRoleDetail(id uint) (*models.Role, error)
+	GetAllPermissions() ([]models.Permission, error)
+	CreateRole(name, description string, codes []string) (*models.Role, error)
+	UpdateRole(id uint, in RoleUpdate) (*models.Role, error)
+	DeleteRole(id uint) error
+	SetUserRoles(userID uint, roleIDs []uint) error
+}
+
+type roleRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) RoleRepository {
+	return &roleRepository{db: db}
+}
+
+var (
+	ErrSystemRole        = apperr.Conflict("system roles cannot be renamed or deleted").WithCode("system_role")
+	ErrUnknownPermission = apperr.Validation("unknown permission code").WithCode("unknown_permission")
+	ErrUnknownRole       = apperr.Validation("unknown role").WithCode("unknown_role")
+)
+
+// SeedRolesAndPermissions đảm bảo danh mục permission và các role hệ thống tồn tại.
+// Role đã có thì giữ nguyên quyền admin đã chỉnh, không ghi đè.
+func (r *roleRepository) SeedRolesAndPermissions() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, code := range service.AllPermissions {
+			perm := models.Permission{Code: code}
+			if err := tx.Where("code = ?", code).FirstOrCreate(&perm).Error; err != nil {
+				return err
+			}
+		}
+
+		for name, codes := range service.DefaultRolePermissions {
+			var count int64
+			if err := tx.Model(&models.Role{}).Where("name = ?", name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			perms, err := findPermissions(tx, codes)
+			if err != nil {
+				return err
+			}
+			role := models.Role{Name: name, IsSystem: true, Permissions: perms}
+			if err := tx.Create(&role).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetAllRoles trả về các role kèm permissions
+func (r *roleRepository) GetAllRoles() ([]models.Role, error) {
+	var roles []models.Role
+	err := r.db.Preload("Permissions").Order("id").Find(&roles).Error
+	return roles, err
+}
+
+// GetRoleDetail lấy 1 role kèm permissions
+func (r *roleRepository) GetRoleDetail(id uint) (*models.Role, error) {
+	var role models.Role
+	if err := r.db.Preload("Permissions").First(&role, id).Error; err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// GetAllPermissions trả về danh mục capability
+func (r *roleRepository) GetAllPermissions() ([]models.Permission, error) {
+	var perms []models.Permission
+	err := r.db.Order("code").Find(&perms).Error
+	return perms, err
+}
+
+// CreateRole tạo role tùy biến với danh sách permission code
+func (r *roleRepository) CreateRole(name, description string, codes []string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		perms, err := findPermissions(tx, codes)
+		if err != nil {
+			return err
+		}
+		role = models.Role{Name: name, Description: description, Permissions: perms}
+		return tx.Create(&role).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// RoleUpdate: các field của role được sửa (nil = giữ nguyên)
+type RoleUpdate struct {
+	Name        *string
+	Description *string
+	Permissions *[]string
+}
+
+// UpdateRole chỉ cập nhật các field được gửi; Permissions khác nil thay toàn bộ permissions.
+// Role hệ thống không được đổi tên.
+func (r *roleRepository) UpdateRole(id uint, in RoleUpdate) (*models.Role, error) {
+	var role models.Role
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&role, id).Error; err != nil {
+			return err
+		}
+		if role.IsSystem && in.Name != nil && *in.Name != role.Name {
+			return ErrSystemRole
+		}
+
+		updates := map[string]interface{}{}
+		if in.Name != nil {
+			updates["name"] = *in.Name
+		}
+		if in.Description != nil {
+			updates["description"] = *in.Description
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&role).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		if in.Permissions != nil {
+			perms, err := findPermissions(tx, *in.Permissions)
+			if err != nil {
+				return err
+			}
+			if err := tx.Model(&role).Association("Permissions").Replace(perms); err != nil {
+				return err
+			}
+		}
+		return tx.Preload("Permissions").First(&role, id).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &role, nil
+}
+
+// DeleteRole xóa role tùy biến cùng các liên kết user_roles / role_permissions
+func (r *roleRepository) DeleteRole(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var role models.Role
+		if err := tx.First(&role, id).Error; err != nil {
+			return err
+		}
+		if role.IsSystem {
+			return ErrSystemRole
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("role_id = ?", id).Delete(&models.UserRole{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+}
+
+// SetUserRoles thay toàn bộ role tùy biến của user (có hiệu lực từ lần đăng nhập kế tiếp)
+func (r *roleRepository) SetUserRoles(userID uint, roleIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			return err
+		}
+		var roles []models.Role
+		if len(roleIDs) > 0 {
+			if err := tx.Where("id IN ?", roleIDs).Find(&roles).Error; err != nil {
+				return err
+			}
+			if len(roles) != len(roleIDs) {
+				return ErrUnknownRole
+			}
+		}
+		return tx.Model(&user).Association("Roles").Replace(roles)
+	})
+}
+
+func findPermissions(tx *gorm.DB, codes []string) ([]models.Permission, error) {
+	perms := []models.Permission{}
+	unique := map[string]bool{}
+	for _, c := range codes {
+		unique[c] = true
+	}
+	if len(unique) == 0 {
+		return perms, nil
+	}
+	if err := tx.Where("code IN ?", codes).Find(&perms).Error; err != nil {
+		return nil, err
+	}
+	if len(perms) != len(unique) {
+		return nil, ErrUnknownPermission
+	}
+	return perms, nil
+}
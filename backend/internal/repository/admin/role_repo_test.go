@@ -0,0 +1,79 @@
+package admin
+
+import (
+	"backend/internal/models"
+	"backend/internal/service"
+	"backend/internal/testdb"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func permissionCodes(role *models.Role) []string {
+	codes := []string{}
+	for _, p := range role.Permissions {
+		codes = append(codes, p.Code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Field không gửi (nil) giữ nguyên giá trị cũ, kể cả description và permissions
+func TestUpdateRoleOnlyChangesSentFields(t *testing.T) {
+	repo := NewRoleRepository(testdb.Open(t))
+	if err := repo.SeedRolesAndPermissions(); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	role, err := repo.CreateRole("auditor", "Xem log", []string{service.PermLogsRead, service.PermUsersRead})
+	if err != nil {
+		t.Fatalf("CreateRole: %v", err)
+	}
+
+	name := "log auditor"
+	empty := []string{}
+	tests := []struct {
+		name      string
+		in        RoleUpdate
+		wantName  string
+		wantDesc  string
+		wantCodes []string
+	}{
+		{"name only", RoleUpdate{Name: &name}, "log auditor", "Xem log", []string{service.PermLogsRead, service.PermUsersRead}},
+		{"nothing sent", RoleUpdate{}, "log auditor", "Xem log", []string{service.PermLogsRead, service.PermUsersRead}},
+		{"empty permissions clear", RoleUpdate{Permissions: &empty}, "log auditor", "Xem log", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := repo.UpdateRole(role.ID, tt.in)
+			if err != nil {
+				t.Fatalf("UpdateRole: %v", err)
+			}
+			stored, err := repo.GetRoleDetail(role.ID)
+			if err != nil {
+				t.Fatalf("GetRoleDetail: %v", err)
+			}
+			for _, r := range []*models.Role{got, stored} {
+				if r.Name != tt.wantName || r.Description != tt.wantDesc || !reflect.DeepEqual(permissionCodes(r), tt.wantCodes) {
+					t.Errorf("role = %q %q %v, want %q %q %v", r.Name, r.Description, permissionCodes(r), tt.wantName, tt.wantDesc, tt.wantCodes)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateRoleRejectsSystemRoleRename(t *testing.T) {
+	repo := NewRoleRepository(testdb.Open(t))
+	if err := repo.SeedRolesAndPermissions(); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	roles, err := repo.GetAllRoles()
+	if err != nil {
+		t.Fatalf("GetAllRoles: %v", err)
+	}
+
+	name := "boss"
+	if _, err := repo.UpdateRole(roles[0].ID, RoleUpdate{Name: &name}); !errors.Is(err, ErrSystemRole) {
+		t.Fatalf("err = %v, want ErrSystemRole", err)
+	}
+}
@@ -1,45 +1,67 @@
 package admin
 
 import (
-    "backend/configs"
     "backend/internal/models"
     "fmt"
+    "strings"
+
+    "gorm.io/gorm"
 )
 
-func SearchAll(keyword string) ([]models.SearchResult, error) {
+// SearchRepository: tìm kiếm nhanh trên nhiều bảng cho trang admin
+type SearchRepository interface {
+    SearchAll(keyword string) ([]models.SearchResult, error)
+}
+
+type searchRepository struct {
+    db *gorm.DB
+}
+
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+    return &searchRepository{db: db}
+}
+
+func (r *searchRepository) SearchAll(keyword string) ([]models.SearchResult, error) {
     var results []models.SearchResult
 
+    // LOWER + CAST để LIKE chạy giống nhau trên MySQL/Postgres/SQLite
+    pattern := "%" + strings.ToLower(keyword) + "%"
+    idText := "CAST(id AS TEXT)"
+    if r.db.Dialector.Name() == "mysql" {
+        idText = "CAST(id AS CHAR)"
+    }
+
     // Sản phẩm
     var products []struct{ ID int; Name string }
-    configs.DB.Raw("SELECT id, name FROM products WHERE name LIKE ?", "%"+keyword+"%").Scan(&products)
+    r.db.Raw("SELECT id, name FROM products WHERE deleted_at IS NULL AND LOWER(name) LIKE ?", pattern).Scan(&products)
     for _, p := range products {
         results = append(results, models.SearchResult{ID: p.ID, Name: p.Name, Type: "product"})
     }
 
     // Nhà cung cấp
     var suppliers []struct{ ID int; Name string }
-    configs.DB.Raw("SELECT id, name FROM suppliers WHERE name LIKE ?", "%"+keyword+"%").Scan(&suppliers)
+    r.db.Raw("SELECT id, name FROM suppliers WHERE deleted_at IS NULL AND LOWER(name) LIKE ?", pattern).Scan(&suppliers)
     for _, s := range suppliers {
         results = append(results, models.SearchResult{ID: s.ID, Name: s.Name, Type: "supplier"})
     }
 
     // Loại (category)
     var categories []struct{ ID int; Name string }
-    configs.DB.Raw("SELECT id, name FROM categories WHERE name LIKE ?", "%"+keyword+"%").Scan(&categories)
+    r.db.Raw("SELECT id, name FROM categories WHERE deleted_at IS NULL AND LOWER(name) LIKE ?", pattern).Scan(&categories)
     for _, c := range categories {
         results = append(results, models.SearchResult{ID: c.ID, Name: c.Name, Type: "category"})
     }
 
     // Đơn hàng (order)
     var orders []struct{ ID int }
-    configs.DB.Raw("SELECT id FROM orders WHERE id LIKE ?", "%"+keyword+"%").Scan(&orders)
+    r.db.Raw("SELECT id FROM orders WHERE "+idText+" LIKE ?", pattern).Scan(&orders)
     for _, o := range orders {
         results = append(results, models.SearchResult{ID: o.ID, Name: fmt.Sprintf("Order #%d", o.ID), Type: "order"})
     }
 
     // Phiếu nhập (purchase)
     var purchases []struct{ ID int }
-    configs.DB.Raw("SELECT id FROM purchases WHERE id LIKE ?", "%"+keyword+"%").Scan(&purchases)
+    r.db.Raw("SELECT id FROM purchases WHERE "+idText+" LIKE ?", pattern).Scan(&purchases)
     for _, p := range purchases {
         results = append(results, models.SearchResult{ID: p.ID, Name: fmt.Sprintf("Purchase #%d", p.ID), Type: "purchase"})
     }
@@ -0,0 +1,85 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"errors"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrVariantNotFound   = apperr.Validation("variant not found").WithCode("variant_not_found")
+	ErrInsufficientStock = apperr.Conflict("insufficient stock").WithCode("insufficient_stock")
+	ErrInvalidChangeType = apperr.Validation("invalid change type").WithCode("invalid_change_type")
+)
+
+// lockVariant đọc variant với SELECT ... FOR UPDATE; phải gọi trong transaction
+func lockVariant(tx *gorm.DB, id uint) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&variant, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrVariantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &variant, nil
+}
+
+// lockVariants khóa nhiều variant theo thứ tự id tăng dần (tránh deadlock giữa 2 transaction)
+func lockVariants(tx *gorm.DB, ids ...uint) (map[uint]*models.ProductVariant, error) {
+	unique := map[uint]bool{}
+	for _, id := range ids {
+		unique[id] = true
+	}
+	sorted := make([]uint, 0, len(unique))
+	for id := range unique {
+		sorted = append(sorted, id)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	result := make(map[uint]*models.ProductVariant, len(sorted))
+	for _, id := range sorted {
+		variant, err := lockVariant(tx, id)
+		if err != nil {
+			return nil, err
+		}
+		result[id] = variant
+	}
+	return result, nil
+}
+
+// setVariantStock ghi tồn kho mới cho variant đã khóa (tăng version để form sửa cũ bị 412)
+func setVariantStock(tx *gorm.DB, variant *models.ProductVariant, stock int) error {
+	if err := tx.Model(&models.ProductVariant{}).Where("id = ?", variant.ID).Updates(map[string]interface{}{
+		"stock":   stock,
+		"version": gorm.Expr("version + 1"),
+	}).Error; err != nil {
+		return err
+	}
+	variant.Stock = stock
+	variant.Version++
+	return nil
+}
+
+// addVariantStock cộng delta vào tồn kho của variant đã khóa; tồn kho không được âm
+func addVariantStock(tx *gorm.DB, variant *models.ProductVariant, delta int) error {
+	if variant.Stock+delta < 0 {
+		return ErrInsufficientStock
+	}
+	return setVariantStock(tx, variant, variant.Stock+delta)
+}
+
+// createInventoryLog ghi 1 dòng sổ kho; lỗi được trả về để transaction rollback
+func createInventoryLog(tx *gorm.DB, variantID uint, changeType string, quantity int, note string) error {
+	log := models.InventoryLog{
+		VariantID:  variantID,
+		ChangeType: changeType,
+		Quantity:   quantity,
+		Note:       note,
+	}
+	return tx.Omit("Variant").Create(&log).Error
+}
@@ -1,45 +1,96 @@
 package admin
 
 import (
-	"backend/configs"
 	"backend/internal/models"
+
+	"gorm.io/gorm"
 )
 
+// SupplierRepository: truy cập dữ liệu supplier cho trang admin
+type SupplierRepository interface {
+	GetAllSuppliers() ([]models.Supplier, error)
+	GetSupplierDetail(id uint) (*models.Supplier, error)
+	CreateSupplier(s *models.Supplier) (*models.Supplier, error)
+	UpdateSupplier(id uint, newData *models.Supplier, version uint) (*models.Supplier, error)
+	DeleteSupplier(id uint) error
+	GetTrashedSuppliers() ([]models.Supplier, error)
+	RestoreSupplier(id uint) error
+	PurgeSupplier(id uint) error
+}
+
+type supplierRepository struct {
+	db *gorm.DB
+}
+
+func NewSupplierRepository(db *gorm.DB) SupplierRepository {
+	return &supplierRepository{db: db}
+}
+
 // Supplier CRUD
-func GetAllSuppliers() ([]models.Supplier, error) {
+func (r *supplierRepository) GetAllSuppliers() ([]models.Supplier, error) {
 	var suppliers []models.Supplier
-	err := configs.DB.Find(&suppliers).Error
+	err := r.db.Find(&suppliers).Error
 	return suppliers, err
 }
 
-func GetSupplierDetail(id uint) (*models.Supplier, error) {
+func (r *supplierRepository) GetSupplierDetail(id uint) (*models.Supplier, error) {
 	var supplier models.Supplier
-	err := configs.DB.Preload("Purchases").First(&supplier, id).Error
+	err := r.db.Preload("Purchases").First(&supplier, id).Error
 	return &supplier, err
 }
 
-func CreateSupplier(s *models.Supplier) (*models.Supplier, error) {
-	if err := configs.DB.Create(s).Error; err != nil {
+func (r *supplierRepository) CreateSupplier(s *models.Supplier) (*models.Supplier, error) {
+	if err := r.db.Create(s).Error; err != nil {
 		return nil, err
 	}
 	return s, nil
 }
 
-func UpdateSupplier(id uint, newData *models.Supplier) (*models.Supplier, error) {
-	var s models.Supplier
-	if err := configs.DB.First(&s, id).Error; err != nil {
+// UpdateSupplier chỉ ghi khi version còn khớp (If-Match)
+func (r *supplierRepository) UpdateSupplier(id uint, newData *models.Supplier, version uint) (*models.Supplier, error) {
+	if err := updateVersioned(r.db, &models.Supplier{}, id, version, map[string]interface{}{
+		"name":    newData.Name,
+		"phone":   newData.Phone,
+		"email":   newData.Email,
+		"address": newData.Address,
+	}); err != nil {
 		return nil, err
 	}
-	s.Name = newData.Name
-	s.Phone = newData.Phone
-	s.Email = newData.Email
-	s.Address = newData.Address
-	if err := configs.DB.Save(&s).Error; err != nil {
+	var s models.Supplier
+	if err := r.db.First(&s, id).Error; err != nil {
 		return nil, err
 	}
 	return &s, nil
 }
 
-func DeleteSupplier(id uint) error {
-	return configs.DB.Delete(&models.Supplier{}, id).Error
+// DeleteSupplier soft-delete, phiếu nhập cũ vẫn giữ tham chiếu tới supplier
+func (r *supplierRepository) DeleteSupplier(id uint) error {
+	res := r.db.Delete(&models.Supplier{}, id)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *supplierRepository) GetTrashedSuppliers() ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	err := r.db.Scopes(onlyTrashed).Order("deleted_at desc").Find(&suppliers).Error
+	return suppliers, err
+}
+
+func (r *supplierRepository) RestoreSupplier(id uint) error {
+	return restoreTrashed(r.db, &models.Supplier{}, id)
+}
+
+// PurgeSupplier bị chặn nếu supplier còn phiếu nhập
+func (r *supplierRepository) PurgeSupplier(id uint) error {
+	if err := checkDependents("supplier", id,
+		dependentCheck{"purchases", r.db.Model(&models.Purchase{}).Where("supplier_id = ?", id)},
+	); err != nil {
+		return err
+	}
+	return purgeTrashed(r.db, &models.Supplier{}, id)
 }
@@ -0,0 +1,47 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+
+	"gorm.io/gorm"
+)
+
+// ErrParentTrashed: không khôi phục được vì bản ghi cha vẫn ở thùng rác (phải khôi phục cha trước)
+var ErrParentTrashed = apperr.Conflict("parent record is in the trash").WithCode("parent_trashed")
+
+// onlyTrashed: scope lấy các bản ghi đã soft-delete
+func onlyTrashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Where("deleted_at IS NOT NULL")
+}
+
+// withTrashed: dùng cho Preload dữ liệu lịch sử (đơn hàng, phiếu nhập) để vẫn thấy bản ghi đã xóa
+func withTrashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
+
+// restoreTrashed khôi phục bản ghi id đang nằm trong thùng rác.
+// Trả gorm.ErrRecordNotFound nếu không có bản ghi đã xóa nào khớp.
+func restoreTrashed(db *gorm.DB, model interface{}, id uint) error {
+	res := db.Unscoped().Model(model).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// purgeTrashed xóa vĩnh viễn bản ghi id; chỉ áp dụng cho bản ghi đã ở thùng rác
+func purgeTrashed(db *gorm.DB, model interface{}, id uint) error {
+	res := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(model)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
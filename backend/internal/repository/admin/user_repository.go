@@ -1,36 +1,78 @@
 package admin
 
 import (
-	"backend/configs"
+	"backend/internal/listquery"
 	"backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// UserRepository: truy cập dữ liệu user cho trang admin
+type UserRepository interface {
+	GetAllUsers(p listquery.Params) ([]models.User, *listquery.Meta, error)
+	GetUserByID(id uint) (*models.User, error)
+	UpdateUser(id uint, newData *models.User) (*models.User, error)
+	UpdateUserRole(id uint, role string) (*models.User, error)
+	DeleteUser(id uint) error
+	GetTrashedUsers() ([]models.User, error)
+	RestoreUser(id uint) error
+	PurgeUser(id uint) error
+	GetAllLoginLogs(p listquery.Params) ([]models.LoginLog, *listquery.Meta, error)
+	GetLoginLogsByUserID(userID uint, p listquery.Params) ([]models.LoginLog, *listquery.Meta, error)
+	CreateLoginLog(log *models.LoginLog) error
+	RevokeAllSessions(userID uint) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
 // ================= GET ALL USERS =================
-func GetAllUsers() ([]models.User, error) {
+// userListSpec: cột được sort / filter ở GET /api/admin/users
+var userListSpec = listquery.Spec{
+	Sorts:       map[string]string{"id": "id", "username": "username", "email": "email", "created_at": "created_at"},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"role": "role", "created": "created_at"},
+}
+
+func (r *userRepository) GetAllUsers(p listquery.Params) ([]models.User, *listquery.Meta, error) {
 	var users []models.User
-	err := configs.DB.Find(&users).Error
-	return users, err
+	meta, err := listquery.Find(r.db, userListSpec, p, &users)
+	return users, meta, err
+}
+
+// ================= GET USER =================
+func (r *userRepository) GetUserByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
 // ================= UPDATE USER =================
-func UpdateUser(id uint, newData *models.User) (*models.User, error) {
+func (r *userRepository) UpdateUser(id uint, newData *models.User) (*models.User, error) {
 	var user models.User
-	if err := configs.DB.First(&user, id).Error; err != nil {
+	if err := r.db.First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	user.Username = newData.Username
 	user.Email = newData.Email
 	user.Phone = newData.Phone
 	user.Address = newData.Address
-	user.Role = newData.Role
-	user.UpdatedAt = configs.DB.NowFunc() 
+	user.UpdatedAt = r.db.NowFunc()
 
-	if err := configs.DB.Model(&user).Updates(map[string]interface{}{
+	// Role không đổi qua đây, dùng UpdateUserRole (cần quyền roles:manage)
+	if err := r.db.Model(&user).Updates(map[string]interface{}{
 		"username":   user.Username,
 		"email":      user.Email,
 		"phone":      user.Phone,
 		"address":    user.Address,
-		"role":       user.Role,
 		"updated_at": user.UpdatedAt,
 	}).Error; err != nil {
 		return nil, err
@@ -39,19 +81,110 @@ func UpdateUser(id uint, newData *models.User) (*models.User, error) {
 	return &user, nil
 }
 
+// ================= UPDATE USER ROLE =================
+func (r *userRepository) UpdateUserRole(id uint, role string) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	var count int64
+	if err := r.db.Model(&models.Role{}).Where("name = ? AND is_system = ?", role, true).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrUnknownRole
+	}
+	if err := r.db.Model(&user).Update("role", role).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // ================= DELETE USER =================
-func DeleteUser(id uint) error {
-	return configs.DB.Delete(&models.User{}, id).Error
+// DeleteUser soft-delete user và thu hồi mọi phiên đăng nhập; đơn hàng / phiếu nhập cũ giữ nguyên
+func (r *userRepository) DeleteUser(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.User{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return NewUserRepository(tx).RevokeAllSessions(id)
+	})
+}
+
+func (r *userRepository) GetTrashedUsers() ([]models.User, error) {
+	var users []models.User
+	err := r.db.Scopes(onlyTrashed).Order("deleted_at desc").Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) RestoreUser(id uint) error {
+	return restoreTrashed(r.db, &models.User{}, id)
 }
-func GetAllLoginLogs() ([]models.LoginLog, error) {
+
+// PurgeUser xóa vĩnh viễn user trong thùng rác cùng dữ liệu đăng nhập / cá nhân của họ.
+// Bị chặn nếu còn đơn hàng (là customer) hoặc phiếu nhập (là staff); staff của đơn và
+// actor trong timeline được set NULL bởi foreign key. LoginLog giữ lại để audit.
+func (r *userRepository) PurgeUser(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Scopes(onlyTrashed).Model(&models.User{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := checkDependents("user", id,
+			dependentCheck{"orders", tx.Model(&models.Order{}).Where("customer_id = ?", id)},
+			dependentCheck{"purchases", tx.Model(&models.Purchase{}).Where("staff_id = ?", id)},
+		); err != nil {
+			return err
+		}
+		owned := []interface{}{
+			&models.RefreshToken{}, &models.PasswordResetToken{}, &models.UserTOTP{},
+			&models.BackupCode{}, &models.EmailChangeRequest{}, &models.UserAddress{}, &models.Cart{},
+		}
+		for _, model := range owned {
+			if err := tx.Where("user_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return purgeTrashed(tx, &models.User{}, id)
+	})
+}
+
+// loginLogListSpec: cột được sort / filter ở GET /api/admin/logs
+var loginLogListSpec = listquery.Spec{
+	Sorts:       map[string]string{"id": "id", "created_at": "created_at"},
+	DefaultSort: "-created_at",
+	Filters:     map[string]string{"user_id": "user_id", "status": "status", "role": "role", "created": "created_at"},
+}
+
+func (r *userRepository) GetAllLoginLogs(p listquery.Params) ([]models.LoginLog, *listquery.Meta, error) {
 	var logs []models.LoginLog
-	err := configs.DB.Order("created_at desc").Find(&logs).Error
-	return logs, err
+	meta, err := listquery.Find(r.db, loginLogListSpec, p, &logs)
+	return logs, meta, err
 }
 
 // Lấy log theo user_id (cho staff/customer)
-func GetLoginLogsByUserID(userID uint) ([]models.LoginLog, error) {
+func (r *userRepository) GetLoginLogsByUserID(userID uint, p listquery.Params) ([]models.LoginLog, *listquery.Meta, error) {
 	var logs []models.LoginLog
-	err := configs.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&logs).Error
-	return logs, err
-}
\ No newline at end of file
+	meta, err := listquery.Find(r.db.Where("user_id = ?", userID), loginLogListSpec, p, &logs)
+	return logs, meta, err
+}
+
+// Ghi 1 dòng LoginLog (vd admin mở khóa tài khoản)
+func (r *userRepository) CreateLoginLog(log *models.LoginLog) error {
+	return r.db.Create(log).Error
+}
+
+// ================= REVOKE SESSIONS =================
+// Thu hồi mọi refresh token còn hiệu lực của user
+func (r *userRepository) RevokeAllSessions(userID uint) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
@@ -0,0 +1,34 @@
+package admin
+
+import (
+	"backend/internal/apperr"
+
+	"gorm.io/gorm"
+)
+
+// ErrVersionConflict: bản ghi đã bị người khác sửa (version trong If-Match đã cũ)
+var ErrVersionConflict = apperr.PreconditionFailed("resource was modified by someone else").WithCode("version_conflict")
+
+// updateVersioned cập nhật bản ghi id chỉ khi version còn khớp, đồng thời tăng version.
+// db có thể kèm sẵn điều kiện phạm vi (vd product_id của variant), bản ghi ngoài phạm vi coi như không tồn tại.
+// Trả gorm.ErrRecordNotFound nếu không có bản ghi, ErrVersionConflict nếu version đã cũ.
+func updateVersioned(db *gorm.DB, model interface{}, id, version uint, updates map[string]interface{}) error {
+	db = db.Session(&gorm.Session{})
+	updates["version"] = gorm.Expr("version + 1")
+	res := db.Model(model).Where("id = ? AND version = ?", id, version).Updates(updates)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		return nil
+	}
+
+	var count int64
+	if err := db.Model(model).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return ErrVersionConflict
+}
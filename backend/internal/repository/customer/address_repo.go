@@ -0,0 +1,144 @@
+package customer
+
+import (
+	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrNoShippingAddress = apperr.Validation("no shipping address").WithCode("shipping_address_required")
+
+// GetMyAddresses lấy sổ địa chỉ của user, địa chỉ mặc định đứng đầu
+func GetMyAddresses(userID uint) ([]models.UserAddress, error) {
+	var addresses []models.UserAddress
+	err := configs.DB.
+		Where("user_id = ?", userID).
+		Order("is_default desc, id desc").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+func GetMyAddress(userID, addressID uint) (*models.UserAddress, error) {
+	var address models.UserAddress
+	if err := configs.DB.Where("user_id = ?", userID).First(&address, addressID).Error; err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// GetShippingAddress chọn địa chỉ giao hàng cho checkout:
+// addressID = 0 thì dùng địa chỉ mặc định
+func GetShippingAddress(userID, addressID uint) (*models.UserAddress, error) {
+	if addressID != 0 {
+		return GetMyAddress(userID, addressID)
+	}
+	var address models.UserAddress
+	err := configs.DB.
+		Where("user_id = ? AND is_default = ?", userID, true).
+		First(&address).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoShippingAddress
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// CreateMyAddress thêm địa chỉ; địa chỉ đầu tiên luôn là mặc định
+func CreateMyAddress(userID uint, address *models.UserAddress) error {
+	address.ID = 0
+	address.UserID = userID
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.UserAddress{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+		return tx.Create(address).Error
+	})
+}
+
+// UpdateMyAddress sửa nội dung địa chỉ; isDefault = nil thì giữ nguyên cờ mặc định
+func UpdateMyAddress(userID, addressID uint, data models.ShippingAddress, isDefault *bool) (*models.UserAddress, error) {
+	var address models.UserAddress
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).First(&address, addressID).Error; err != nil {
+			return err
+		}
+		address.Address = data
+		// Không bỏ cờ mặc định trực tiếp, phải chọn địa chỉ khác làm mặc định
+		if isDefault != nil && *isDefault && !address.IsDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+			address.IsDefault = true
+		}
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// SetDefaultAddress đặt 1 địa chỉ làm mặc định
+func SetDefaultAddress(userID, addressID uint) (*models.UserAddress, error) {
+	var address models.UserAddress
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).First(&address, addressID).Error; err != nil {
+			return err
+		}
+		if err := clearDefaultAddress(tx, userID); err != nil {
+			return err
+		}
+		address.IsDefault = true
+		return tx.Model(&address).Update("is_default", true).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &address, nil
+}
+
+// DeleteMyAddress xóa địa chỉ; nếu xóa địa chỉ mặc định thì địa chỉ mới nhất còn lại thành mặc định.
+// Order đã đặt không bị ảnh hưởng vì đã snapshot địa chỉ.
+func DeleteMyAddress(userID, addressID uint) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		var address models.UserAddress
+		if err := tx.Where("user_id = ?", userID).First(&address, addressID).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+		if !address.IsDefault {
+			return nil
+		}
+		var next models.UserAddress
+		err := tx.Where("user_id = ?", userID).Order("id desc").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+func clearDefaultAddress(tx *gorm.DB, userID uint) error {
+	return tx.Model(&models.UserAddress{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
@@ -0,0 +1,82 @@
+// Package customer chứa truy vấn cho API của customer đã đăng nhập (đơn hàng, hồ sơ, sổ địa chỉ).
+// Đơn hàng dùng interface + inject như repository/admin; hồ sơ và sổ địa chỉ vẫn dùng configs.DB toàn cục.
+package customer
+
+import (
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"backend/internal/service"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrOrderNotCancellable = apperr.Conflict("only pending orders can be cancelled").WithCode("order_not_cancellable")
+
+// OrderRepository: order của customer đang đăng nhập
+type OrderRepository interface {
+	GetMyOrders(customerID uint, status string) ([]models.Order, error)
+	GetMyOrderDetail(customerID, orderID uint) (*models.Order, error)
+	CancelMyOrder(actor *service.Claims, orderID uint, note string) error
+}
+
+// OrderStatusChanger chuyển status order (transition, hook, history) trong transaction của caller;
+// admin.OrderRepository thỏa interface này
+type OrderStatusChanger interface {
+	ChangeOrderStatus(tx *gorm.DB, order *models.Order, status string, actor *service.Claims, note string) error
+}
+
+type orderRepository struct {
+	db       *gorm.DB
+	statuses OrderStatusChanger
+}
+
+func NewOrderRepository(db *gorm.DB, statuses OrderStatusChanger) OrderRepository {
+	return &orderRepository{db: db, statuses: statuses}
+}
+
+// GetMyOrders lấy các order của customer, mới nhất trước
+func (r *orderRepository) GetMyOrders(customerID uint, status string) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.
+		Preload("Items.Variant.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("customer_id = ?", customerID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at desc").Find(&orders).Error
+	return orders, err
+}
+
+// GetMyOrderDetail lấy 1 order của customer kèm timeline
+func (r *orderRepository) GetMyOrderDetail(customerID, orderID uint) (*models.Order, error) {
+	var order models.Order
+	err := r.db.
+		Preload("Items.Variant.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("History", func(db *gorm.DB) *gorm.DB { return db.Order("created_at, id") }).
+		Where("customer_id = ?", customerID).
+		First(&order, orderID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CancelMyOrder cho phép customer tự hủy order khi còn pending (hoàn kho qua hook cancel)
+func (r *orderRepository) CancelMyOrder(actor *service.Claims, orderID uint, note string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("customer_id = ?", actor.UserID).
+			First(&order, orderID).Error; err != nil {
+			return err
+		}
+		if order.Status != service.OrderPending {
+			return ErrOrderNotCancellable
+		}
+		if note == "" {
+			note = "Cancelled by customer"
+		}
+		return r.statuses.ChangeOrderStatus(tx, &order, service.OrderCancelled, actor, note)
+	})
+}
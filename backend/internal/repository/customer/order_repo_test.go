@@ -0,0 +1,59 @@
+package customer
+
+import (
+	"backend/internal/models"
+	"backend/internal/service"
+	"backend/internal/testdb"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// fakeStatusChanger ghi lại các lần chuyển status thay cho order repository admin
+type fakeStatusChanger struct {
+	calls []string
+}
+
+func (f *fakeStatusChanger) ChangeOrderStatus(tx *gorm.DB, order *models.Order, status string, actor *service.Claims, note string) error {
+	f.calls = append(f.calls, order.Status+"->"+status+": "+note)
+	return nil
+}
+
+// Hủy đơn đi qua OrderStatusChanger được inject, chỉ với order pending của chính customer
+func TestCancelMyOrderUsesInjectedStatusChanger(t *testing.T) {
+	db := testdb.Open(t)
+	owner := models.User{Username: "owner", Email: "owner@example.com", PasswordHash: "x", Role: "customer"}
+	other := models.User{Username: "other", Email: "other@example.com", PasswordHash: "x", Role: "customer"}
+	for _, u := range []*models.User{&owner, &other} {
+		if err := db.Create(u).Error; err != nil {
+			t.Fatalf("create user: %v", err)
+		}
+	}
+	pending := models.Order{CustomerID: owner.ID, Status: service.OrderPending}
+	shipped := models.Order{CustomerID: owner.ID, Status: service.OrderShipped}
+	for _, o := range []*models.Order{&pending, &shipped} {
+		if err := db.Omit("Customer", "Staff").Create(o).Error; err != nil {
+			t.Fatalf("create order: %v", err)
+		}
+	}
+
+	statuses := &fakeStatusChanger{}
+	repo := NewOrderRepository(db, statuses)
+	actor := &service.Claims{UserID: owner.ID, Role: "customer"}
+
+	if err := repo.CancelMyOrder(&service.Claims{UserID: other.ID, Role: "customer"}, pending.ID, ""); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("other customer: err = %v, want ErrRecordNotFound", err)
+	}
+	if err := repo.CancelMyOrder(actor, shipped.ID, ""); !errors.Is(err, ErrOrderNotCancellable) {
+		t.Errorf("shipped order: err = %v, want ErrOrderNotCancellable", err)
+	}
+	if err := repo.CancelMyOrder(actor, pending.ID, ""); err != nil {
+		t.Fatalf("CancelMyOrder: %v", err)
+	}
+
+	want := "pending->cancelled: Cancelled by customer"
+	if len(statuses.calls) != 1 || statuses.calls[0] != want {
+		t.Errorf("calls = %q, want [%q]", statuses.calls, want)
+	}
+}
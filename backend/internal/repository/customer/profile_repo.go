@@ -0,0 +1,145 @@
+package customer
+
+import (
+	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailChangeTTL: thời gian hiệu lực của link xác nhận email mới
+const EmailChangeTTL = time.Hour
+
+var (
+	ErrUsernameTaken     = apperr.Conflict("username already exists").WithCode("username_taken")
+	ErrEmailTaken        = apperr.Conflict("email already exists").WithCode("email_taken")
+	ErrInvalidEmailToken = apperr.BadRequest("invalid or expired email confirmation token").WithCode("invalid_token")
+)
+
+// ProfileUpdate: các field customer được tự sửa (nil = giữ nguyên)
+type ProfileUpdate struct {
+	Username *string
+	Phone    *string
+	Address  *string
+}
+
+func GetProfile(userID uint) (*models.User, error) {
+	var user models.User
+	if err := configs.DB.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateProfile cập nhật username / phone / address của chính user
+func UpdateProfile(userID uint, in ProfileUpdate) (*models.User, error) {
+	user, err := GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{}
+	if in.Username != nil && *in.Username != user.Username {
+		var count int64
+		if err := configs.DB.Model(&models.User{}).Where("username = ? AND id <> ?", *in.Username, userID).Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrUsernameTaken
+		}
+		updates["username"] = *in.Username
+	}
+	if in.Phone != nil {
+		updates["phone"] = *in.Phone
+	}
+	if in.Address != nil {
+		updates["address"] = *in.Address
+	}
+	if len(updates) == 0 {
+		return user, nil
+	}
+
+	if err := configs.DB.Model(user).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return GetProfile(userID)
+}
+
+// ChangePassword đổi mật khẩu và thu hồi mọi refresh token (đăng xuất các phiên khác)
+func ChangePassword(userID uint, passwordHash string) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Update("password_hash", passwordHash).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", userID).
+			Update("revoked_at", time.Now()).Error
+	})
+}
+
+// CreateEmailChangeRequest tạo yêu cầu đổi email, trả về token gốc để gửi tới email mới
+func CreateEmailChangeRequest(userID uint, newEmail string) (string, error) {
+	var count int64
+	if err := configs.DB.Unscoped().Model(&models.User{}).Where("email = ?", newEmail).Count(&count).Error; err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", ErrEmailTaken
+	}
+
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.EmailChangeRequest{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		req := models.EmailChangeRequest{
+			UserID:    userID,
+			NewEmail:  newEmail,
+			TokenHash: utils.HashToken(raw),
+			ExpiresAt: now.Add(EmailChangeTTL),
+		}
+		return tx.Create(&req).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// ConfirmEmailChange áp dụng email mới khi bấm link xác nhận (dùng 1 lần)
+func ConfirmEmailChange(raw string) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		var req models.EmailChangeRequest
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(raw)).
+			First(&req).Error; err != nil {
+			return ErrInvalidEmailToken
+		}
+		if req.UsedAt != nil || time.Now().After(req.ExpiresAt) {
+			return ErrInvalidEmailToken
+		}
+
+		var count int64
+		if err := tx.Model(&models.User{}).Where("email = ? AND id <> ?", req.NewEmail, req.UserID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmailTaken
+		}
+
+		if err := tx.Model(&req).Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", req.UserID).Update("email", req.NewEmail).Error
+	})
+}
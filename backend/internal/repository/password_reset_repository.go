@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PasswordResetTTL: thời gian hiệu lực của link đặt lại mật khẩu
+const PasswordResetTTL = 30 * time.Minute
+
+var ErrInvalidResetToken = apperr.BadRequest("invalid or expired reset token").WithCode("invalid_token")
+
+// CreatePasswordResetToken vô hiệu các token cũ chưa dùng và tạo token mới cho user
+func CreatePasswordResetToken(userID uint) (string, error) {
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.PasswordResetToken{}).
+			Where("user_id = ? AND used_at IS NULL", userID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+		token := models.PasswordResetToken{
+			UserID:    userID,
+			TokenHash: utils.HashToken(raw),
+			ExpiresAt: now.Add(PasswordResetTTL),
+		}
+		return tx.Create(&token).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// ResetPassword đánh dấu token đã dùng, đổi mật khẩu và thu hồi mọi refresh token của user
+func ResetPassword(raw, passwordHash string) (*models.User, error) {
+	var user models.User
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		var token models.PasswordResetToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(raw)).
+			First(&token).Error; err != nil {
+			return ErrInvalidResetToken
+		}
+		if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+			return ErrInvalidResetToken
+		}
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			return ErrInvalidResetToken
+		}
+
+		now := time.Now()
+		if err := tx.Model(&token).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&user).Update("password_hash", passwordHash).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.RefreshToken{}).
+			Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Update("revoked_at", now).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RegistrationTTL: thời gian hiệu lực của link xác nhận đăng ký
+const RegistrationTTL = time.Hour
+
+var (
+	ErrUsernameTaken        = apperr.Conflict("username already exists").WithCode("username_taken")
+	ErrEmailTaken           = apperr.Conflict("email already exists").WithCode("email_taken")
+	ErrInvalidConfirmToken  = apperr.BadRequest("invalid confirmation token").WithCode("invalid_token")
+	ErrConfirmTokenExpired  = apperr.BadRequest("confirmation token expired").WithCode("token_expired")
+	ErrRegistrationNotFound = apperr.NotFound("pending registration not found").WithCode("registration_not_found")
+)
+
+// CheckUserAvailable kiểm tra username / email chưa có user nào dùng (kể cả user đã xóa mềm,
+// vì unique index vẫn giữ giá trị của họ)
+// và username chưa bị 1 đăng ký chờ xác nhận khác (email khác) giữ chỗ
+func CheckUserAvailable(username, email string) error {
+	var count int64
+	if err := configs.DB.Unscoped().Model(&models.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrEmailTaken
+	}
+	if err := configs.DB.Unscoped().Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUsernameTaken
+	}
+	if err := configs.DB.Model(&models.PendingRegistration{}).
+		Where("username = ? AND email <> ? AND confirmed_at IS NULL AND expires_at > ?", username, email, time.Now()).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUsernameTaken
+	}
+	return nil
+}
+
+// SavePendingRegistration tạo (hoặc thay thế) đăng ký chờ xác nhận theo email, trả về token gốc
+func SavePendingRegistration(p *models.PendingRegistration) (string, error) {
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	p.TokenHash = utils.HashToken(raw)
+	p.ExpiresAt = time.Now().Add(RegistrationTTL)
+	p.ConfirmedAt = nil
+
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("email = ?", p.Email).Delete(&models.PendingRegistration{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(p).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return raw, nil
+}
+
+// RenewPendingRegistration cấp token mới cho đăng ký chưa xác nhận (resend confirmation)
+func RenewPendingRegistration(email string) (*models.PendingRegistration, string, error) {
+	var p models.PendingRegistration
+	if err := configs.DB.Where("email = ? AND confirmed_at IS NULL", email).First(&p).Error; err != nil {
+		return nil, "", ErrRegistrationNotFound
+	}
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	err = configs.DB.Model(&p).Updates(map[string]interface{}{
+		"token_hash": utils.HashToken(raw),
+		"expires_at": time.Now().Add(RegistrationTTL),
+	}).Error
+	if err != nil {
+		return nil, "", err
+	}
+	return &p, raw, nil
+}
+
+// ConfirmPendingRegistration tạo user từ đăng ký chờ xác nhận (dùng 1 lần).
+// Bấm lại link đã xác nhận trả về alreadyConfirmed = true thay vì lỗi.
+func ConfirmPendingRegistration(raw string) (alreadyConfirmed bool, err error) {
+	err = configs.DB.Transaction(func(tx *gorm.DB) error {
+		var p models.PendingRegistration
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(raw)).
+			First(&p).Error; err != nil {
+			return ErrInvalidConfirmToken
+		}
+		if p.ConfirmedAt != nil {
+			alreadyConfirmed = true
+			return nil
+		}
+		if time.Now().After(p.ExpiresAt) {
+			return ErrConfirmTokenExpired
+		}
+
+		var count int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("email = ?", p.Email).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrEmailTaken
+		}
+		if err := tx.Unscoped().Model(&models.User{}).Where("username = ?", p.Username).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrUsernameTaken
+		}
+
+		user := models.User{
+			Username:     p.Username,
+			PasswordHash: p.PasswordHash,
+			Email:        p.Email,
+			Phone:        p.Phone,
+			Address:      p.Address,
+			Role:         "customer",
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			return err
+		}
+		return tx.Model(&p).Update("confirmed_at", time.Now()).Error
+	})
+	return alreadyConfirmed, err
+}
@@ -0,0 +1,192 @@
+package shop
+
+import (
+	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"backend/internal/utils"
+	"errors"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInsufficientStock = apperr.Conflict("insufficient stock").WithCode("insufficient_stock")
+	ErrVariantNotFound   = apperr.NotFound("variant not found").WithCode("variant_not_found")
+	ErrCartItemNotFound  = apperr.NotFound("cart item not found").WithCode("cart_item_not_found")
+)
+
+// UnitPrice tính giá bán của variant sau khi áp dụng Product.Discount (%)
+func UnitPrice(v models.ProductVariant) float64 {
+	base := v.Price
+	if base <= 0 {
+		base = v.Product.Price
+	}
+	price := base * (1 - v.Product.Discount/100)
+	return math.Round(price*100) / 100
+}
+
+// GetOrCreateUserCart lấy giỏ hàng của customer, tạo mới nếu chưa có
+func GetOrCreateUserCart(userID uint) (*models.Cart, error) {
+	cart := models.Cart{UserID: &userID}
+	if err := configs.DB.Where("user_id = ?", userID).FirstOrCreate(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// GetGuestCart lấy giỏ hàng của khách vãng lai theo token
+func GetGuestCart(token string) (*models.Cart, error) {
+	var cart models.Cart
+	if err := configs.DB.Where("guest_token = ?", token).First(&cart).Error; err != nil {
+		return nil, err
+	}
+	return &cart, nil
+}
+
+// CreateGuestCart tạo giỏ hàng mới kèm token ẩn danh
+func CreateGuestCart() (*models.Cart, string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, "", err
+	}
+	cart := models.Cart{GuestToken: &token}
+	if err := configs.DB.Create(&cart).Error; err != nil {
+		return nil, "", err
+	}
+	return &cart, token, nil
+}
+
+// LoadCart lấy giỏ hàng kèm items và tính lại giá
+func LoadCart(cartID uint) (*models.Cart, error) {
+	var cart models.Cart
+	err := configs.DB.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).
+		Preload("Items.Variant.Product").
+		First(&cart, cartID).Error
+	if err != nil {
+		return nil, err
+	}
+	for i := range cart.Items {
+		item := &cart.Items[i]
+		item.UnitPrice = UnitPrice(item.Variant)
+		item.Subtotal = item.UnitPrice * float64(item.Quantity)
+		cart.Total += item.Subtotal
+	}
+	return &cart, nil
+}
+
+// AddCartItem thêm variant vào giỏ (cộng dồn nếu đã có), kiểm tra tồn kho
+func AddCartItem(cartID, variantID uint, quantity int) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		var variant models.ProductVariant
+		if err := tx.First(&variant, variantID).Error; err != nil {
+			return ErrVariantNotFound
+		}
+		// Không cho thêm variant của sản phẩm đã bị xóa hoặc chưa publish
+		if err := tx.Select("id").Where("is_published = ?", true).First(&models.Product{}, variant.ProductID).Error; err != nil {
+			return ErrVariantNotFound
+		}
+
+		var item models.CartItem
+		err := tx.Where("cart_id = ? AND variant_id = ?", cartID, variantID).First(&item).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if item.Quantity+quantity > variant.Stock {
+			return ErrInsufficientStock
+		}
+
+		if item.ID == 0 {
+			item = models.CartItem{CartID: cartID, VariantID: variantID, Quantity: quantity}
+			return tx.Create(&item).Error
+		}
+		return tx.Model(&item).Update("quantity", item.Quantity+quantity).Error
+	})
+}
+
+// UpdateCartItem đặt lại số lượng của 1 item, kiểm tra tồn kho
+func UpdateCartItem(cartID, itemID uint, quantity int) error {
+	var item models.CartItem
+	if err := configs.DB.Preload("Variant").Where("cart_id = ?", cartID).First(&item, itemID).Error; err != nil {
+		return ErrCartItemNotFound
+	}
+	if quantity > item.Variant.Stock {
+		return ErrInsufficientStock
+	}
+	return configs.DB.Model(&item).Update("quantity", quantity).Error
+}
+
+// RemoveCartItem xóa 1 item khỏi giỏ
+func RemoveCartItem(cartID, itemID uint) error {
+	res := configs.DB.Where("cart_id = ?", cartID).Delete(&models.CartItem{}, itemID)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return ErrCartItemNotFound
+	}
+	return nil
+}
+
+// ClearCart xóa toàn bộ items trong giỏ
+func ClearCart(cartID uint) error {
+	return configs.DB.Where("cart_id = ?", cartID).Delete(&models.CartItem{}).Error
+}
+
+// MergeGuestCart gộp giỏ hàng khách vãng lai vào giỏ của customer sau khi đăng nhập.
+// Số lượng cộng dồn được giới hạn bởi tồn kho; item của sản phẩm đã xóa hoặc chưa publish
+// bị bỏ qua; giỏ khách bị xóa sau khi gộp.
+func MergeGuestCart(token string, userID uint) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		var guest models.Cart
+		if err := tx.Preload("Items.Variant.Product").Where("guest_token = ?", token).First(&guest).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		cart := models.Cart{UserID: &userID}
+		if err := tx.Where("user_id = ?", userID).FirstOrCreate(&cart).Error; err != nil {
+			return err
+		}
+
+		for _, gi := range guest.Items {
+			// Product đã soft delete không được preload (zero value) nên cũng bị bỏ qua ở đây
+			if !gi.Variant.Product.IsPublished {
+				continue
+			}
+
+			var item models.CartItem
+			err := tx.Where("cart_id = ? AND variant_id = ?", cart.ID, gi.VariantID).First(&item).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			qty := item.Quantity + gi.Quantity
+			if qty > gi.Variant.Stock {
+				qty = gi.Variant.Stock
+			}
+			if qty <= 0 {
+				continue
+			}
+
+			if item.ID == 0 {
+				item = models.CartItem{CartID: cart.ID, VariantID: gi.VariantID, Quantity: qty}
+				if err := tx.Create(&item).Error; err != nil {
+					return err
+				}
+			} else if err := tx.Model(&item).Update("quantity", qty).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("cart_id = ?", guest.ID).Delete(&models.CartItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&guest).Error
+	})
+}
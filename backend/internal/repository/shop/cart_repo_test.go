@@ -0,0 +1,77 @@
+package shop
+
+import (
+	"backend/internal/models"
+	"backend/internal/testdb"
+	"errors"
+	"testing"
+)
+
+func TestAddCartItemRejectsUnpublishedProduct(t *testing.T) {
+	db := testdb.Open(t)
+	customer, product, variants := seedCatalog(t, db, 5)
+	if err := db.Model(&product).Update("is_published", false).Error; err != nil {
+		t.Fatalf("unpublish product: %v", err)
+	}
+
+	cart, err := GetOrCreateUserCart(customer.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserCart: %v", err)
+	}
+	if err := AddCartItem(cart.ID, variants[0].ID, 1); !errors.Is(err, ErrVariantNotFound) {
+		t.Fatalf("err = %v, want ErrVariantNotFound", err)
+	}
+	if n := count(t, db, &models.CartItem{}); n != 0 {
+		t.Errorf("cart items = %d, want 0", n)
+	}
+}
+
+// Item của sản phẩm chưa publish / đã xóa bị bỏ qua khi gộp, item còn bán được giữ lại
+func TestMergeGuestCartSkipsUnavailableProducts(t *testing.T) {
+	db := testdb.Open(t)
+	customer, product, variants := seedCatalog(t, db, 5)
+	hidden := models.Product{Name: "Áo ẩn", CategoryID: product.CategoryID, Price: 50}
+	deleted := models.Product{Name: "Áo đã xóa", CategoryID: product.CategoryID, Price: 50}
+	for _, p := range []*models.Product{&hidden, &deleted} {
+		if err := db.Create(p).Error; err != nil {
+			t.Fatalf("create product: %v", err)
+		}
+	}
+	others := []models.ProductVariant{
+		{ProductID: hidden.ID, SKU: "H", Stock: 5},
+		{ProductID: deleted.ID, SKU: "D", Stock: 5},
+	}
+	if err := db.Create(&others).Error; err != nil {
+		t.Fatalf("create variants: %v", err)
+	}
+
+	guest, token, err := CreateGuestCart()
+	if err != nil {
+		t.Fatalf("CreateGuestCart: %v", err)
+	}
+	for _, v := range []models.ProductVariant{variants[0], others[0], others[1]} {
+		if err := AddCartItem(guest.ID, v.ID, 2); err != nil {
+			t.Fatalf("AddCartItem %s: %v", v.SKU, err)
+		}
+	}
+	if err := db.Model(&hidden).Update("is_published", false).Error; err != nil {
+		t.Fatalf("unpublish product: %v", err)
+	}
+	if err := db.Delete(&deleted).Error; err != nil {
+		t.Fatalf("soft delete product: %v", err)
+	}
+
+	if err := MergeGuestCart(token, customer.ID); err != nil {
+		t.Fatalf("MergeGuestCart: %v", err)
+	}
+	items, err := GetCartCheckoutItems(customer.ID)
+	if err != nil {
+		t.Fatalf("GetCartCheckoutItems: %v", err)
+	}
+	if len(items) != 1 || items[0].VariantID != variants[0].ID || items[0].Quantity != 2 {
+		t.Errorf("items = %+v, want only %s x2", items, variants[0].SKU)
+	}
+	if _, err := GetGuestCart(token); err == nil {
+		t.Error("guest cart still exists after merge")
+	}
+}
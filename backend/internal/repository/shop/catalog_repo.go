@@ -0,0 +1,148 @@
+// Package shop chứa truy vấn của storefront: catalog, giỏ hàng và checkout.
+// Khác với repository/admin, các hàm ở đây đọc thẳng configs.DB (chưa inject *gorm.DB);
+// test thay DB bằng testdb.Open.
+package shop
+
+import (
+	"backend/configs"
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// CatalogFilter gom các tham số lọc / sắp xếp / phân trang của storefront
+type CatalogFilter struct {
+	CategoryID uint
+	Group      string
+	Size       string
+	Color      string
+	MinPrice   float64
+	MaxPrice   float64
+	Sort       string
+	Page       int
+	PerPage    int
+}
+
+// GetCatalogProducts trả về các sản phẩm đã publish theo bộ lọc, kèm tổng số bản ghi
+func GetCatalogProducts(f CatalogFilter) ([]models.ShopProduct, int64, error) {
+	query := configs.DB.Model(&models.Product{}).
+		Joins("JOIN categories ON categories.id = products.category_id").
+		Where("products.is_published = ? AND categories.deleted_at IS NULL", true)
+
+	if f.CategoryID != 0 {
+		query = query.Where("products.category_id = ?", f.CategoryID)
+	}
+	if f.Group != "" {
+		query = query.Where("categories.group_name = ?", f.Group)
+	}
+	// Size và color phải cùng nằm trên một biến thể
+	if f.Size != "" || f.Color != "" {
+		sub := configs.DB.Model(&models.ProductVariant{}).Select("product_id")
+		if f.Size != "" {
+			sub = sub.Where("size = ?", f.Size)
+		}
+		if f.Color != "" {
+			sub = sub.Where("color = ?", f.Color)
+		}
+		query = query.Where("products.id IN (?)", sub)
+	}
+	if f.MinPrice > 0 {
+		query = query.Where("products.discounted_price >= ?", f.MinPrice)
+	}
+	if f.MaxPrice > 0 {
+		query = query.Where("products.discounted_price <= ?", f.MaxPrice)
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var products []models.Product
+	err := query.
+		Select("products.*").
+		Preload("Category").
+		Preload("Variants").
+		Order(catalogOrder(f.Sort)).
+		Offset((f.Page - 1) * f.PerPage).
+		Limit(f.PerPage).
+		Find(&products).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]models.ShopProduct, 0, len(products))
+	for _, p := range products {
+		result = append(result, toShopProduct(p))
+	}
+	return result, total, nil
+}
+
+// GetCatalogProductDetail lấy 1 sản phẩm đã publish
+func GetCatalogProductDetail(id uint) (*models.ShopProduct, error) {
+	var product models.Product
+	err := configs.DB.
+		Preload("Category").
+		Preload("Variants").
+		Where("is_published = ?", true).
+		First(&product, id).Error
+	if err != nil {
+		return nil, err
+	}
+	result := toShopProduct(product)
+	return &result, nil
+}
+
+// GetCatalogCategories trả về danh mục để FE dựng bộ lọc
+func GetCatalogCategories() ([]models.Category, error) {
+	var categories []models.Category
+	err := configs.DB.Order("group_name, name").Find(&categories).Error
+	return categories, err
+}
+
+func catalogOrder(sort string) string {
+	switch sort {
+	case "price_asc":
+		return "products.discounted_price ASC"
+	case "price_desc":
+		return "products.discounted_price DESC"
+	default: // newest
+		return "products.created_at DESC"
+	}
+}
+
+func toShopProduct(p models.Product) models.ShopProduct {
+	sp := models.ShopProduct{
+		ID:              p.ID,
+		Name:            p.Name,
+		Description:     p.Description,
+		Image:           p.Image,
+		Price:           p.Price,
+		Discount:        p.Discount,
+		DiscountedPrice: p.DiscountedPrice,
+		CategoryID:      p.CategoryID,
+		CategoryName:    p.Category.Name,
+		GroupName:       p.Category.GroupName,
+		CreatedAt:       p.CreatedAt,
+		Variants:        make([]models.ShopVariant, 0, len(p.Variants)),
+	}
+	for _, v := range p.Variants {
+		// Giá hiển thị phải đúng bằng giá cart / checkout sẽ tính
+		v.Product = p
+		inStock := v.Stock > 0
+		if inStock {
+			sp.InStock = true
+		}
+		sp.Variants = append(sp.Variants, models.ShopVariant{
+			ID:      v.ID,
+			Size:    v.Size,
+			Color:   v.Color,
+			Price:   UnitPrice(v),
+			SKU:     v.SKU,
+			Image:   v.Image,
+			InStock: inStock,
+		})
+	}
+	return sp
+}
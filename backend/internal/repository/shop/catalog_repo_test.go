@@ -0,0 +1,34 @@
+package shop
+
+import (
+	"backend/internal/testdb"
+	"testing"
+)
+
+// Giá variant trên catalog phải bằng giá checkout tính (giá riêng hoặc giá product, sau discount)
+func TestCatalogVariantPriceMatchesCheckout(t *testing.T) {
+	db := testdb.Open(t)
+	_, product, variants := seedCatalog(t, db, 5)
+
+	detail, err := GetCatalogProductDetail(product.ID)
+	if err != nil {
+		t.Fatalf("GetCatalogProductDetail: %v", err)
+	}
+	want := map[uint]float64{variants[0].ID: 180, variants[1].ID: 90}
+	if len(detail.Variants) != len(want) {
+		t.Fatalf("variants = %d, want %d", len(detail.Variants), len(want))
+	}
+	for _, v := range detail.Variants {
+		if v.Price != want[v.ID] {
+			t.Errorf("variant %s price = %v, want %v", v.SKU, v.Price, want[v.ID])
+		}
+	}
+
+	list, _, err := GetCatalogProducts(CatalogFilter{Page: 1, PerPage: 10})
+	if err != nil {
+		t.Fatalf("GetCatalogProducts: %v", err)
+	}
+	if len(list) != 1 || len(list[0].Variants) != 2 || list[0].Variants[1].Price != want[list[0].Variants[1].ID] {
+		t.Errorf("list = %+v", list)
+	}
+}
@@ -0,0 +1,168 @@
+package shop
+
+import (
+	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"errors"
+	"sort"
+	"strconv"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrEmptyOrder = apperr.Validation("order has no items").WithCode("empty_order")
+
+// CheckoutItem là 1 dòng hàng khách muốn mua
+type CheckoutItem struct {
+	VariantID uint `json:"variant_id" validate:"required"`
+	Quantity  int  `json:"quantity" validate:"min=1"`
+}
+
+// GetCartCheckoutItems chuyển giỏ hàng của customer thành danh sách dòng hàng
+func GetCartCheckoutItems(userID uint) ([]CheckoutItem, error) {
+	var items []models.CartItem
+	err := configs.DB.
+		Joins("JOIN carts ON carts.id = cart_items.cart_id").
+		Where("carts.user_id = ?", userID).
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make([]CheckoutItem, 0, len(items))
+	for _, it := range items {
+		result = append(result, CheckoutItem{VariantID: it.VariantID, Quantity: it.Quantity})
+	}
+	return result, nil
+}
+
+// PlaceOrder tạo Order + OrderItem (snapshot giá), trừ tồn kho và ghi InventoryLog "sale"
+// trong cùng 1 transaction. Các variant được khóa (SELECT ... FOR UPDATE) theo thứ tự id
+// để 2 đơn hàng tranh nhau món cuối cùng không thể cùng thành công.
+// Nếu clearCart = true thì giỏ hàng của customer được làm trống sau khi đặt hàng.
+func PlaceOrder(customerID uint, paymentMethod string, shipping models.ShippingAddress, items []CheckoutItem, clearCart bool) (*models.Order, error) {
+	// Gộp các dòng trùng variant và sắp xếp để khóa theo thứ tự cố định (tránh deadlock)
+	quantities := map[uint]int{}
+	for _, it := range items {
+		quantities[it.VariantID] += it.Quantity
+	}
+	variantIDs := make([]uint, 0, len(quantities))
+	for id := range quantities {
+		variantIDs = append(variantIDs, id)
+	}
+	sort.Slice(variantIDs, func(i, j int) bool { return variantIDs[i] < variantIDs[j] })
+	if len(variantIDs) == 0 {
+		return nil, ErrEmptyOrder
+	}
+
+	var order models.Order
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		var variants []models.ProductVariant
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ?", variantIDs).
+			Order("id").
+			Find(&variants).Error; err != nil {
+			return err
+		}
+		if len(variants) != len(variantIDs) {
+			return ErrVariantNotFound
+		}
+
+		var orderItems []models.OrderItem
+		var total float64
+		for _, v := range variants {
+			if err := tx.First(&v.Product, v.ProductID).Error; err != nil {
+				// Sản phẩm đã bị xóa (soft delete) thì variant coi như không còn bán
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrVariantNotFound
+				}
+				return err
+			}
+			// Sản phẩm chưa publish không hiện trên catalog nên cũng không được bán
+			if !v.Product.IsPublished {
+				return ErrVariantNotFound
+			}
+			qty := quantities[v.ID]
+			if v.Stock < qty {
+				return ErrInsufficientStock.WithDetails(map[string]interface{}{"sku": v.SKU, "available": v.Stock})
+			}
+			price := UnitPrice(v)
+			total += price * float64(qty)
+			orderItems = append(orderItems, models.OrderItem{
+				VariantID: v.ID,
+				Quantity:  qty,
+				Price:     price,
+			})
+		}
+
+		order = models.Order{
+			CustomerID:    customerID,
+			Status:        "pending",
+			PaymentMethod: paymentMethod,
+			Total:         total,
+			Shipping:      shipping,
+		}
+		if err := tx.Omit("Customer", "Staff", "Items", "History").Create(&order).Error; err != nil {
+			return err
+		}
+		history := models.OrderStatusHistory{
+			OrderID:   order.ID,
+			ActorID:   &customerID,
+			ActorRole: "customer",
+			NewStatus: order.Status,
+			Note:      "Order placed",
+		}
+		if err := tx.Omit("Actor").Create(&history).Error; err != nil {
+			return err
+		}
+
+		for i := range orderItems {
+			orderItems[i].OrderID = order.ID
+			if err := tx.Omit("Order", "Variant").Create(&orderItems[i]).Error; err != nil {
+				return err
+			}
+
+			// Trừ kho có điều kiện: dù có khóa vẫn chặn tồn kho âm ở tầng SQL
+			res := tx.Model(&models.ProductVariant{}).
+				Where("id = ? AND stock >= ?", orderItems[i].VariantID, orderItems[i].Quantity).
+				Updates(map[string]interface{}{
+					"stock":   gorm.Expr("stock - ?", orderItems[i].Quantity),
+					"version": gorm.Expr("version + 1"),
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return ErrInsufficientStock
+			}
+
+			log := models.InventoryLog{
+				VariantID:  orderItems[i].VariantID,
+				ChangeType: "sale",
+				Quantity:   orderItems[i].Quantity,
+				Note:       "Order #" + strconv.Itoa(int(order.ID)),
+			}
+			if err := tx.Omit("Variant").Create(&log).Error; err != nil {
+				return err
+			}
+		}
+
+		if clearCart {
+			if err := tx.Where("cart_id IN (?)",
+				tx.Model(&models.Cart{}).Select("id").Where("user_id = ?", customerID),
+			).Delete(&models.CartItem{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configs.DB.Preload("Items.Variant.Product").First(&order, order.ID).Error; err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
@@ -0,0 +1,197 @@
+package shop
+
+import (
+	"backend/internal/models"
+	"backend/internal/testdb"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// seedCatalog tạo 1 customer và 1 product giảm giá 10% với 2 variant (giá riêng 200 / theo product 100)
+func seedCatalog(t *testing.T, db *gorm.DB, stock int) (models.User, models.Product, []models.ProductVariant) {
+	t.Helper()
+	customer := models.User{Username: "customer", Email: "customer@example.com", PasswordHash: "x", Role: "customer"}
+	category := models.Category{Name: "Áo", GroupName: "Đồ nam"}
+	for _, v := range []interface{}{&customer, &category} {
+		if err := db.Create(v).Error; err != nil {
+			t.Fatalf("create %T: %v", v, err)
+		}
+	}
+	product := models.Product{Name: "Áo thun", CategoryID: category.ID, Price: 100, Discount: 10}
+	if err := db.Create(&product).Error; err != nil {
+		t.Fatalf("create product: %v", err)
+	}
+	variants := []models.ProductVariant{
+		{ProductID: product.ID, SKU: "A", Price: 200, Stock: stock},
+		{ProductID: product.ID, SKU: "B", Stock: stock},
+	}
+	if err := db.Create(&variants).Error; err != nil {
+		t.Fatalf("create variants: %v", err)
+	}
+	return customer, product, variants
+}
+
+func stockOf(t *testing.T, db *gorm.DB, id uint) int {
+	t.Helper()
+	var v models.ProductVariant
+	if err := db.First(&v, id).Error; err != nil {
+		t.Fatalf("load variant %d: %v", id, err)
+	}
+	return v.Stock
+}
+
+func count(t *testing.T, db *gorm.DB, model interface{}) int64 {
+	t.Helper()
+	var n int64
+	if err := db.Model(model).Count(&n).Error; err != nil {
+		t.Fatalf("count %T: %v", model, err)
+	}
+	return n
+}
+
+func TestPlaceOrder(t *testing.T) {
+	db := testdb.Open(t)
+	customer, _, variants := seedCatalog(t, db, 5)
+
+	// Dòng trùng variant được gộp lại
+	items := []CheckoutItem{
+		{VariantID: variants[0].ID, Quantity: 1},
+		{VariantID: variants[1].ID, Quantity: 2},
+		{VariantID: variants[0].ID, Quantity: 1},
+	}
+	order, err := PlaceOrder(customer.ID, "cod", models.ShippingAddress{RecipientName: "A", Street: "1 Street"}, items, false)
+	if err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+
+	// 2 x 180 (200 - 10%) + 2 x 90 (giá product 100 - 10%)
+	if order.Total != 540 {
+		t.Errorf("total = %v, want 540", order.Total)
+	}
+	if order.Status != "pending" || order.Shipping.Street != "1 Street" {
+		t.Errorf("order = %+v", order)
+	}
+	if len(order.Items) != 2 {
+		t.Fatalf("items = %d, want 2", len(order.Items))
+	}
+	for _, v := range variants {
+		if got := stockOf(t, db, v.ID); got != 3 {
+			t.Errorf("variant %s stock = %d, want 3", v.SKU, got)
+		}
+	}
+
+	var logs []models.InventoryLog
+	db.Where("change_type = ?", "sale").Find(&logs)
+	if len(logs) != 2 {
+		t.Errorf("sale logs = %d, want 2", len(logs))
+	}
+	if n := count(t, db, &models.OrderStatusHistory{}); n != 1 {
+		t.Errorf("history rows = %d, want 1", n)
+	}
+}
+
+func TestPlaceOrderRollsBackOnError(t *testing.T) {
+	tests := []struct {
+		name    string
+		items   func(v []models.ProductVariant) []CheckoutItem
+		prepare func(t *testing.T, db *gorm.DB, p models.Product)
+		wantErr error
+	}{
+		{
+			name: "insufficient stock",
+			items: func(v []models.ProductVariant) []CheckoutItem {
+				return []CheckoutItem{{VariantID: v[0].ID, Quantity: 1}, {VariantID: v[1].ID, Quantity: 3}}
+			},
+			wantErr: ErrInsufficientStock,
+		},
+		{
+			name: "unknown variant",
+			items: func(v []models.ProductVariant) []CheckoutItem {
+				return []CheckoutItem{{VariantID: v[0].ID, Quantity: 1}, {VariantID: 999, Quantity: 1}}
+			},
+			wantErr: ErrVariantNotFound,
+		},
+		{
+			name: "product in trash",
+			items: func(v []models.ProductVariant) []CheckoutItem {
+				return []CheckoutItem{{VariantID: v[0].ID, Quantity: 1}}
+			},
+			prepare: func(t *testing.T, db *gorm.DB, p models.Product) {
+				if err := db.Delete(&p).Error; err != nil {
+					t.Fatalf("soft delete product: %v", err)
+				}
+			},
+			wantErr: ErrVariantNotFound,
+		},
+		{
+			name: "unpublished product",
+			items: func(v []models.ProductVariant) []CheckoutItem {
+				return []CheckoutItem{{VariantID: v[1].ID, Quantity: 1}}
+			},
+			prepare: func(t *testing.T, db *gorm.DB, p models.Product) {
+				if err := db.Model(&p).Update("is_published", false).Error; err != nil {
+					t.Fatalf("unpublish product: %v", err)
+				}
+			},
+			wantErr: ErrVariantNotFound,
+		},
+		{
+			name:    "no items",
+			items:   func([]models.ProductVariant) []CheckoutItem { return nil },
+			wantErr: ErrEmptyOrder,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testdb.Open(t)
+			customer, product, variants := seedCatalog(t, db, 2)
+			if tt.prepare != nil {
+				tt.prepare(t, db, product)
+			}
+
+			_, err := PlaceOrder(customer.ID, "cod", models.ShippingAddress{}, tt.items(variants), false)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			for _, v := range variants {
+				if got := stockOf(t, db, v.ID); got != 2 {
+					t.Errorf("variant %s stock = %d, want 2", v.SKU, got)
+				}
+			}
+			for _, model := range []interface{}{&models.Order{}, &models.OrderItem{}, &models.InventoryLog{}} {
+				if n := count(t, db, model); n != 0 {
+					t.Errorf("%T rows = %d, want 0", model, n)
+				}
+			}
+		})
+	}
+}
+
+func TestPlaceOrderFromCartClearsCart(t *testing.T) {
+	db := testdb.Open(t)
+	customer, _, variants := seedCatalog(t, db, 5)
+
+	cart, err := GetOrCreateUserCart(customer.ID)
+	if err != nil {
+		t.Fatalf("GetOrCreateUserCart: %v", err)
+	}
+	if err := AddCartItem(cart.ID, variants[1].ID, 2); err != nil {
+		t.Fatalf("AddCartItem: %v", err)
+	}
+	items, err := GetCartCheckoutItems(customer.ID)
+	if err != nil {
+		t.Fatalf("GetCartCheckoutItems: %v", err)
+	}
+
+	if _, err := PlaceOrder(customer.ID, "cod", models.ShippingAddress{}, items, true); err != nil {
+		t.Fatalf("PlaceOrder: %v", err)
+	}
+	if n := count(t, db, &models.CartItem{}); n != 0 {
+		t.Errorf("cart items = %d, want 0", n)
+	}
+	if got := stockOf(t, db, variants[1].ID); got != 3 {
+		t.Errorf("stock = %d, want 3", got)
+	}
+}
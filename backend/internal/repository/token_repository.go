@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"backend/configs"
+	"backend/internal/apperr"
+	"backend/internal/models"
+	"backend/internal/service"
+	"backend/internal/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrInvalidRefreshToken = apperr.Unauthorized("invalid refresh token").WithCode("invalid_refresh_token")
+
+// CreateRefreshToken sinh refresh token mới cho user, trả về token gốc (chỉ gửi cho client 1 lần)
+func CreateRefreshToken(userID uint, ip, userAgent string) (string, *models.RefreshToken, error) {
+	return createRefreshToken(configs.DB, userID, ip, userAgent)
+}
+
+func createRefreshToken(tx *gorm.DB, userID uint, ip, userAgent string) (string, *models.RefreshToken, error) {
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	rt := models.RefreshToken{
+		UserID:    userID,
+		TokenHash: utils.HashToken(raw),
+		IP:        ip,
+		UserAgent: userAgent,
+		ExpiresAt: time.Now().Add(service.RefreshTokenTTL),
+	}
+	if err := tx.Create(&rt).Error; err != nil {
+		return "", nil, err
+	}
+	return raw, &rt, nil
+}
+
+// RotateRefreshToken đổi refresh token cũ lấy token mới.
+// Token đã bị thu hồi mà vẫn được dùng lại => nghi bị lộ, thu hồi toàn bộ phiên của user.
+func RotateRefreshToken(raw, ip, userAgent string) (*models.User, string, error) {
+	var user models.User
+	var newRaw string
+	var reused bool
+
+	err := configs.DB.Transaction(func(tx *gorm.DB) error {
+		var old models.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", utils.HashToken(raw)).
+			First(&old).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+		if old.RevokedAt != nil {
+			reused = true
+			return ErrInvalidRefreshToken
+		}
+		if time.Now().After(old.ExpiresAt) {
+			return ErrInvalidRefreshToken
+		}
+		if err := tx.First(&user, old.UserID).Error; err != nil {
+			return ErrInvalidRefreshToken
+		}
+
+		var next *models.RefreshToken
+		var err error
+		newRaw, next, err = createRefreshToken(tx, old.UserID, ip, userAgent)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		return tx.Model(&old).Updates(map[string]interface{}{
+			"revoked_at":     now,
+			"replaced_by_id": next.ID,
+		}).Error
+	})
+
+	if reused {
+		var old models.RefreshToken
+		if configs.DB.Where("token_hash = ?", utils.HashToken(raw)).First(&old).Error == nil {
+			RevokeAllRefreshTokens(old.UserID)
+		}
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return &user, newRaw, nil
+}
+
+// RevokeRefreshToken thu hồi 1 refresh token của user (logout phiên hiện tại)
+func RevokeRefreshToken(userID uint, raw string) error {
+	return configs.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND token_hash = ? AND revoked_at IS NULL", userID, utils.HashToken(raw)).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllRefreshTokens thu hồi mọi refresh token còn hiệu lực của user (logout mọi phiên)
+func RevokeAllRefreshTokens(userID uint) error {
+	return configs.DB.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAccessToken đưa jti của access token vào danh sách thu hồi
+func RevokeAccessToken(claims *service.Claims) error {
+	if claims.ID == "" {
+		return nil
+	}
+	expiresAt := time.Now().Add(service.AccessTokenTTL)
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	// Dọn các jti đã quá hạn: token hết hạn thì ParseToken đã từ chối, không cần giữ trong danh sách
+	if err := PruneRevokedTokens(); err != nil {
+		return err
+	}
+	revoked := models.RevokedToken{JTI: claims.ID, UserID: claims.UserID, ExpiresAt: expiresAt}
+	return configs.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&revoked).Error
+}
+
+// PruneRevokedTokens xóa các dòng revoked_tokens đã qua thời điểm hết hạn của access token
+func PruneRevokedTokens() error {
+	return configs.DB.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error
+}
+
+// IsAccessTokenRevoked kiểm tra jti có nằm trong danh sách thu hồi không
+func IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := configs.DB.Model(&models.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
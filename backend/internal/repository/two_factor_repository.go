@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"backend/configs"
+	"backend/internal/models"
+	"backend/internal/service"
+	"backend/internal/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GetUserTOTP lấy cấu hình TOTP của user, nil nếu chưa từng enrol
+func GetUserTOTP(userID uint) (*models.UserTOTP, error) {
+	var totp models.UserTOTP
+	err := configs.DB.Where("user_id = ?", userID).First(&totp).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &totp, nil
+}
+
+// SaveTOTPSecret lưu secret mới (chưa bật) cho user đang enrol
+func SaveTOTPSecret(userID uint, secret string) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.UserTOTP{UserID: userID, Secret: secret}).Error
+	})
+}
+
+// EnableTOTP bật TOTP sau khi user xác nhận mã đúng và thay bộ backup code
+func EnableTOTP(userID uint, step int64, backupCodes []string) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		if err := tx.Model(&models.UserTOTP{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"enabled":        true,
+			"enabled_at":     now,
+			"last_used_step": step,
+		}).Error; err != nil {
+			return err
+		}
+		return replaceBackupCodes(tx, userID, backupCodes)
+	})
+}
+
+// DisableTOTP tắt 2FA và xóa backup code
+func DisableTOTP(userID uint) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.BackupCode{}).Error
+	})
+}
+
+// ReplaceBackupCodes tạo lại toàn bộ backup code
+func ReplaceBackupCodes(userID uint, codes []string) error {
+	return configs.DB.Transaction(func(tx *gorm.DB) error {
+		return replaceBackupCodes(tx, userID, codes)
+	})
+}
+
+func replaceBackupCodes(tx *gorm.DB, userID uint, codes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.BackupCode{}).Error; err != nil {
+		return err
+	}
+	for _, c := range codes {
+		code := models.BackupCode{UserID: userID, CodeHash: utils.HashToken(service.NormalizeBackupCode(c))}
+		if err := tx.Create(&code).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseTOTPStep ghi nhận time step vừa dùng; trả false nếu mã đã được dùng (replay)
+func UseTOTPStep(userID uint, step int64) (bool, error) {
+	res := configs.DB.Model(&models.UserTOTP{}).
+		Where("user_id = ? AND last_used_step < ?", userID, step).
+		Update("last_used_step", step)
+	return res.RowsAffected > 0, res.Error
+}
+
+// UseBackupCode đánh dấu 1 backup code đã dùng; trả false nếu không hợp lệ
+func UseBackupCode(userID uint, code string) (bool, error) {
+	res := configs.DB.Model(&models.BackupCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, utils.HashToken(service.NormalizeBackupCode(code))).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
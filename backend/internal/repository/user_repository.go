@@ -1,8 +1,14 @@
+// Package repository chứa truy vấn cho xác thực: user, login log, refresh token / jti bị thu hồi,
+// đăng ký chờ xác nhận, reset mật khẩu và 2FA. Các hàm dùng configs.DB toàn cục;
+// middleware và auth controller gọi trực tiếp nên chưa tách interface.
 package repository
 
 import (
 	"backend/configs"
 	"backend/internal/models"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 func CreateUser(user *models.User) error {
@@ -14,13 +20,87 @@ func GetUserByEmail(email string) (*models.User, error) {
 	err := configs.DB.Where("email = ?", email).First(&user).Error
 	return &user, err
 }
+func GetUserByID(id uint) (*models.User, error) {
+	var user models.User
+	err := configs.DB.First(&user, id).Error
+	return &user, err
+}
+
+// UserExists: user còn tồn tại (chưa bị soft delete / purge)
+func UserExists(id uint) (bool, error) {
+	var count int64
+	err := configs.DB.Model(&models.User{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
 func CreateLoginLog(log *models.LoginLog) error {
 	return configs.DB.Create(log).Error
 }
 
+// Các status LoginLog đưa bộ đếm đăng nhập sai của tài khoản về 0
+var loginResetStatuses = []string{"success", "unlocked", "password_reset"}
+
+// GetConsecutiveFailedLogins đếm số lần sai liên tiếp của user kể từ lần reset gần nhất
+func GetConsecutiveFailedLogins(userID uint) (int, time.Time, error) {
+	since := configs.DB.Model(&models.LoginLog{}).
+		Select("COALESCE(MAX(created_at), ?)", time.Unix(0, 0)).
+		Where("user_id = ? AND status IN ?", userID, loginResetStatuses)
+
+	return failedLoginStats(configs.DB.Model(&models.LoginLog{}).
+		Where("user_id = ? AND status = ? AND created_at > (?)", userID, "failed", since))
+}
+
+// GetRecentFailedLoginsByIP đếm số lần sai từ 1 IP trong khoảng thời gian gần đây
+func GetRecentFailedLoginsByIP(ip string, window time.Duration) (int, time.Time, error) {
+	return failedLoginStats(configs.DB.Model(&models.LoginLog{}).
+		Where("ip = ? AND status = ? AND created_at > ?", ip, "failed", time.Now().Add(-window)))
+}
+
+// failedLoginStats trả về số dòng và created_at mới nhất của query.
+// Không dùng MAX(created_at) vì SQLite trả kết quả aggregate dạng text, không scan được vào time.Time.
+func failedLoginStats(query *gorm.DB) (int, time.Time, error) {
+	var count int64
+	if err := query.Session(&gorm.Session{}).Count(&count).Error; err != nil || count == 0 {
+		return 0, time.Time{}, err
+	}
+	var last models.LoginLog
+	if err := query.Session(&gorm.Session{}).Select("created_at").Order("created_at desc").Take(&last).Error; err != nil {
+		return 0, time.Time{}, err
+	}
+	return int(count), last.CreatedAt, nil
+}
+
 // Lấy log theo user_id
 func GetLoginLogsByUserID(userID uint) ([]models.LoginLog, error) {
 	var logs []models.LoginLog
 	err := configs.DB.Where("user_id = ?", userID).Order("created_at desc").Find(&logs).Error
 	return logs, err
-}
\ No newline at end of file
+}
+
+// GetUserRoleIDs trả về id của role hệ thống (theo User.Role) cùng các role tùy biến được gán
+func GetUserRoleIDs(user *models.User) ([]uint, error) {
+	var ids []uint
+	err := configs.DB.Model(&models.Role{}).
+		Where("name = ?", user.Role).
+		Or("id IN (?)", configs.DB.Model(&models.UserRole{}).Select("role_id").Where("user_id = ?", user.ID)).
+		Order("id").
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetPermissionCodes resolve danh sách capability từ role ids trong JWT.
+// roleName dùng làm fallback cho token cũ chưa có role_ids.
+func GetPermissionCodes(roleIDs []uint, roleName string) ([]string, error) {
+	var codes []string
+	query := configs.DB.Model(&models.Permission{}).
+		Distinct("permissions.code").
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id")
+	if len(roleIDs) > 0 {
+		query = query.Where("roles.id IN ?", roleIDs)
+	} else {
+		query = query.Where("roles.name = ?", roleName)
+	}
+	err := query.Pluck("permissions.code", &codes).Error
+	return codes, err
+}
@@ -0,0 +1,117 @@
+// Package response ghi mọi response JSON theo 1 envelope:
+// thành công {"data": ..., "meta": ...}, lỗi {"error": {"code", "message", "details"}}.
+package response
+
+import (
+	"backend/internal/apperr"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+var statusByKind = map[apperr.Kind]int{
+	apperr.KindInternal:             http.StatusInternalServerError,
+	apperr.KindBadRequest:           http.StatusBadRequest,
+	apperr.KindValidation:           http.StatusUnprocessableEntity,
+	apperr.KindUnauthorized:         http.StatusUnauthorized,
+	apperr.KindForbidden:            http.StatusForbidden,
+	apperr.KindNotFound:             http.StatusNotFound,
+	apperr.KindConflict:             http.StatusConflict,
+	apperr.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	apperr.KindPreconditionRequired: http.StatusPreconditionRequired,
+	apperr.KindLocked:               http.StatusLocked,
+	apperr.KindTooManyRequests:      http.StatusTooManyRequests,
+}
+
+type errorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// success: data luôn có mặt (kể cả list rỗng), meta chỉ có ở API danh sách
+type success struct {
+	Data interface{} `json:"data"`
+	Meta interface{} `json:"meta,omitempty"`
+}
+
+type failure struct {
+	Error errorBody `json:"error"`
+}
+
+func write(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// JSON ghi {"data": data} với status tùy ý
+func JSON(w http.ResponseWriter, status int, data interface{}) {
+	write(w, status, success{Data: data})
+}
+
+// OK ghi 200 {"data": data}
+func OK(w http.ResponseWriter, data interface{}) {
+	JSON(w, http.StatusOK, data)
+}
+
+// Created ghi 201 {"data": data}
+func Created(w http.ResponseWriter, data interface{}) {
+	JSON(w, http.StatusCreated, data)
+}
+
+// List ghi 200 {"data": items, "meta": meta} (meta: tổng số, trang, cursor, ...)
+func List(w http.ResponseWriter, items interface{}, meta interface{}) {
+	write(w, http.StatusOK, success{Data: items, Meta: meta})
+}
+
+// Message ghi 200 {"data": {"message": message}} cho các thao tác không trả dữ liệu
+func Message(w http.ResponseWriter, message string) {
+	OK(w, map[string]string{"message": message})
+}
+
+// Error ghi lỗi theo envelope. Lỗi có kiểu (apperr.Error) dùng status/code của nó, kể cả khi
+// bị Internal bọc ngoài (vd lỗi ràng buộc DB trong "Failed to create ...");
+// gorm.ErrRecordNotFound ở bất kỳ đâu trong chuỗi lỗi -> 404; còn lại -> 500 và chỉ log nguyên nhân.
+func Error(w http.ResponseWriter, err error) {
+	var appErr *apperr.Error
+	typed := errors.As(err, &appErr)
+	if typed && appErr.Kind == apperr.KindInternal {
+		if inner := typedCause(appErr.Err); inner != nil {
+			appErr = inner
+		}
+	}
+	switch {
+	case typed && appErr.Kind != apperr.KindInternal:
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		appErr = apperr.NotFound("Resource not found")
+	case typed:
+		log.Printf("internal error: %v", err)
+	default:
+		log.Printf("internal error: %v", err)
+		appErr = apperr.Internal("Internal server error", err)
+	}
+
+	status, ok := statusByKind[appErr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	write(w, status, failure{Error: errorBody{
+		Code:    appErr.Code,
+		Message: appErr.Message,
+		Details: appErr.Details,
+	}})
+}
+
+// typedCause: lỗi có kiểu (khác Internal) đầu tiên trong chuỗi nguyên nhân
+func typedCause(err error) *apperr.Error {
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*apperr.Error); ok && e.Kind != apperr.KindInternal {
+			return e
+		}
+	}
+	return nil
+}
@@ -3,68 +3,112 @@ package routes
 import (
 	adminCtrl "backend/internal/controllers/admin"
 	"backend/internal/middlewares"
+	"backend/internal/service"
+
 	"github.com/gorilla/mux"
-	
 )
 
-func SetupAdminRoutes(r *mux.Router) {
-	
+// AdminControllers gom các controller admin đã được khởi tạo sẵn dependency (xem cmd/main.go)
+type AdminControllers struct {
+	Users      *adminCtrl.UserController
+	Roles      *adminCtrl.RoleController
+	Suppliers  *adminCtrl.SupplierController
+	Purchases  *adminCtrl.PurchaseController
+	Categories *adminCtrl.CategoryController
+	Products   *adminCtrl.ProductController
+	Inventory  *adminCtrl.InventoryController
+	Orders     *adminCtrl.OrderController
+	Search     *adminCtrl.SearchController
+}
+
+func SetupAdminRoutes(r *mux.Router, c AdminControllers) {
+	// Mọi route admin đều cần JWT và khai báo quyền riêng qua RequirePermission
 	adminRouter := r.PathPrefix("/api/admin").Subrouter()
-	adminRouter.Use(middlewares.JWTMiddleware) 
+	adminRouter.Use(middlewares.JWTMiddleware)
+	can := middlewares.RequirePermission
+
 	// Users
-	adminRouter.HandleFunc("/users", adminCtrl.GetAllUsers).Methods("GET")
-	adminRouter.HandleFunc("/users/{id:[0-9]+}", adminCtrl.EditUser).Methods("PUT")
-	adminRouter.HandleFunc("/users/{id:[0-9]+}", adminCtrl.DeleteUser).Methods("DELETE")
-	adminRouter.HandleFunc("/logs", adminCtrl.GetUserLogsHandler).Methods("GET")
-	
-	supplierRouter := r.PathPrefix("/api/admin/suppliers").Subrouter()
-	supplierRouter.HandleFunc("", adminCtrl.GetAllSuppliers).Methods("GET")
-	supplierRouter.HandleFunc("", adminCtrl.CreateSupplier).Methods("POST")
-	supplierRouter.HandleFunc("/{id:[0-9]+}", adminCtrl.GetSupplierDetail).Methods("GET")
-	supplierRouter.HandleFunc("/{id:[0-9]+}", adminCtrl.EditSupplier).Methods("PUT")
-	supplierRouter.HandleFunc("/{id:[0-9]+}", adminCtrl.DeleteSupplier).Methods("DELETE")
+	adminRouter.Handle("/users", can(service.PermUsersRead, c.Users.GetAllUsers)).Methods("GET")
+	adminRouter.Handle("/users/{id:[0-9]+}", can(service.PermUsersManage, c.Users.EditUser)).Methods("PUT")
+	adminRouter.Handle("/users/{id:[0-9]+}", can(service.PermUsersManage, c.Users.DeleteUser)).Methods("DELETE")
+	adminRouter.Handle("/users/trash", can(service.PermUsersRead, c.Users.GetTrashedUsers)).Methods("GET")
+	adminRouter.Handle("/users/{id:[0-9]+}/restore", can(service.PermUsersManage, c.Users.RestoreUser)).Methods("POST")
+	adminRouter.Handle("/users/{id:[0-9]+}/purge", can(service.PermTrashPurge, c.Users.PurgeUser)).Methods("DELETE")
+	adminRouter.Handle("/users/{id:[0-9]+}/unlock", can(service.PermUsersManage, c.Users.UnlockUser)).Methods("POST")
+	adminRouter.Handle("/users/{id:[0-9]+}/revoke-sessions", can(service.PermUsersManage, c.Users.RevokeUserSessions)).Methods("POST")
+	adminRouter.Handle("/users/{id:[0-9]+}/role", can(service.PermRolesManage, c.Users.EditUserRole)).Methods("PUT")
+	adminRouter.Handle("/users/{id:[0-9]+}/roles", can(service.PermRolesManage, c.Roles.SetUserRoles)).Methods("PUT")
+	adminRouter.Handle("/logs", can(service.PermLogsRead, c.Users.GetUserLogsHandler)).Methods("GET")
+
+	// Roles & permissions
+	adminRouter.Handle("/roles", can(service.PermRolesManage, c.Roles.GetAllRoles)).Methods("GET")
+	adminRouter.Handle("/roles", can(service.PermRolesManage, c.Roles.CreateRole)).Methods("POST")
+	adminRouter.Handle("/roles/{id:[0-9]+}", can(service.PermRolesManage, c.Roles.GetRoleDetail)).Methods("GET")
+	adminRouter.Handle("/roles/{id:[0-9]+}", can(service.PermRolesManage, c.Roles.EditRole)).Methods("PUT")
+	adminRouter.Handle("/roles/{id:[0-9]+}", can(service.PermRolesManage, c.Roles.DeleteRole)).Methods("DELETE")
+	adminRouter.Handle("/permissions", can(service.PermRolesManage, c.Roles.GetAllPermissions)).Methods("GET")
+
+	// Suppliers
+	adminRouter.Handle("/suppliers", can(service.PermSuppliersRead, c.Suppliers.GetAllSuppliers)).Methods("GET")
+	adminRouter.Handle("/suppliers", can(service.PermSuppliersManage, c.Suppliers.CreateSupplier)).Methods("POST")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}", can(service.PermSuppliersRead, c.Suppliers.GetSupplierDetail)).Methods("GET")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}", can(service.PermSuppliersManage, c.Suppliers.EditSupplier)).Methods("PUT")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}", can(service.PermSuppliersManage, c.Suppliers.DeleteSupplier)).Methods("DELETE")
+	adminRouter.Handle("/suppliers/trash", can(service.PermSuppliersRead, c.Suppliers.GetTrashedSuppliers)).Methods("GET")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}/restore", can(service.PermSuppliersManage, c.Suppliers.RestoreSupplier)).Methods("POST")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}/purge", can(service.PermTrashPurge, c.Suppliers.PurgeSupplier)).Methods("DELETE")
 
 	// Supplier-scoped purchases
-	supplierRouter.HandleFunc("/{id:[0-9]+}/purchases", adminCtrl.GetPurchasesBySupplier).Methods("GET")
-	supplierRouter.HandleFunc("/{id:[0-9]+}/purchases", adminCtrl.CreatePurchaseForSupplier).Methods("POST")
-	supplierRouter.HandleFunc("/{id:[0-9]+}/purchases/{purchaseId:[0-9]+}", adminCtrl.EditPurchaseForSupplier).Methods("PUT")
-	supplierRouter.HandleFunc("/{id:[0-9]+}/purchases/{purchaseId:[0-9]+}", adminCtrl.DeletePurchaseForSupplier).Methods("DELETE")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}/purchases", can(service.PermPurchasesRead, c.Purchases.GetPurchasesBySupplier)).Methods("GET")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}/purchases", can(service.PermPurchasesCreate, c.Purchases.CreatePurchaseForSupplier)).Methods("POST")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}/purchases/{purchaseId:[0-9]+}", can(service.PermPurchasesManage, c.Purchases.EditPurchaseForSupplier)).Methods("PUT")
+	adminRouter.Handle("/suppliers/{id:[0-9]+}/purchases/{purchaseId:[0-9]+}", can(service.PermPurchasesManage, c.Purchases.DeletePurchaseForSupplier)).Methods("DELETE")
 
 	// Global purchases (optional)
-	adminRouter.HandleFunc("/purchases", adminCtrl.GetAllPurchasesGlobal).Methods("GET")
-	adminRouter.HandleFunc("/purchases", adminCtrl.CreatePurchaseGlobal).Methods("POST")
-	adminRouter.HandleFunc("/purchases/{id:[0-9]+}", adminCtrl.EditPurchaseGlobal).Methods("PUT")
-	adminRouter.HandleFunc("/purchases/{id:[0-9]+}", adminCtrl.DeletePurchaseGlobal).Methods("DELETE")
-    // Categories & Products
-	adminRouter.HandleFunc("/categories", adminCtrl.GetAllCategories).Methods("GET")
-	adminRouter.HandleFunc("/categories", adminCtrl.CreateCategory).Methods("POST")
-	adminRouter.HandleFunc("/categories/{id:[0-9]+}", adminCtrl.EditCategory).Methods("PUT")
-	adminRouter.HandleFunc("/categories/{id:[0-9]+}", adminCtrl.DeleteCategory).Methods("DELETE")
-	adminRouter.HandleFunc("/categories/{id:[0-9]+}", adminCtrl.GetCategoryDetail).Methods("GET")
+	adminRouter.Handle("/purchases", can(service.PermPurchasesRead, c.Purchases.GetAllPurchasesGlobal)).Methods("GET")
+	adminRouter.Handle("/purchases", can(service.PermPurchasesCreate, c.Purchases.CreatePurchaseGlobal)).Methods("POST")
+	adminRouter.Handle("/purchases/{id:[0-9]+}", can(service.PermPurchasesManage, c.Purchases.EditPurchaseGlobal)).Methods("PUT")
+	adminRouter.Handle("/purchases/{id:[0-9]+}", can(service.PermPurchasesManage, c.Purchases.DeletePurchaseGlobal)).Methods("DELETE")
+
+	// Categories & Products
+	adminRouter.Handle("/categories", can(service.PermCatalogRead, c.Categories.GetAllCategories)).Methods("GET")
+	adminRouter.Handle("/categories", can(service.PermCatalogManage, c.Categories.CreateCategory)).Methods("POST")
+	adminRouter.Handle("/categories/{id:[0-9]+}", can(service.PermCatalogManage, c.Categories.EditCategory)).Methods("PUT")
+	adminRouter.Handle("/categories/{id:[0-9]+}", can(service.PermCatalogManage, c.Categories.DeleteCategory)).Methods("DELETE")
+	adminRouter.Handle("/categories/{id:[0-9]+}", can(service.PermCatalogRead, c.Categories.GetCategoryDetail)).Methods("GET")
+	adminRouter.Handle("/categories/trash", can(service.PermCatalogRead, c.Categories.GetTrashedCategories)).Methods("GET")
+	adminRouter.Handle("/categories/{id:[0-9]+}/restore", can(service.PermCatalogManage, c.Categories.RestoreCategory)).Methods("POST")
+	adminRouter.Handle("/categories/{id:[0-9]+}/purge", can(service.PermTrashPurge, c.Categories.PurgeCategory)).Methods("DELETE")
 
-	adminRouter.HandleFunc("/products", adminCtrl.GetAllProducts).Methods("GET")
-	adminRouter.HandleFunc("/products/{id:[0-9]+}", adminCtrl.GetProductDetail).Methods("GET")
-	adminRouter.HandleFunc("/products", adminCtrl.CreateProduct).Methods("POST")
-	adminRouter.HandleFunc("/products/{id:[0-9]+}", adminCtrl.EditProduct).Methods("PUT")
-	adminRouter.HandleFunc("/products/{id:[0-9]+}", adminCtrl.DeleteProduct).Methods("DELETE")
+	adminRouter.Handle("/products", can(service.PermCatalogRead, c.Products.GetAllProducts)).Methods("GET")
+	adminRouter.Handle("/products/{id:[0-9]+}", can(service.PermCatalogRead, c.Products.GetProductDetail)).Methods("GET")
+	adminRouter.Handle("/products", can(service.PermCatalogManage, c.Products.CreateProduct)).Methods("POST")
+	adminRouter.Handle("/products/{id:[0-9]+}", can(service.PermCatalogManage, c.Products.EditProduct)).Methods("PUT")
+	adminRouter.Handle("/products/{id:[0-9]+}", can(service.PermCatalogManage, c.Products.DeleteProduct)).Methods("DELETE")
+	adminRouter.Handle("/products/{id:[0-9]+}/publish", can(service.PermCatalogManage, c.Products.PublishProduct)).Methods("PATCH")
+	adminRouter.Handle("/products/trash", can(service.PermCatalogRead, c.Products.GetTrashedProducts)).Methods("GET")
+	adminRouter.Handle("/products/{id:[0-9]+}/restore", can(service.PermCatalogManage, c.Products.RestoreProduct)).Methods("POST")
+	adminRouter.Handle("/products/{id:[0-9]+}/purge", can(service.PermTrashPurge, c.Products.PurgeProduct)).Methods("DELETE")
 
 	// Variants
-	adminRouter.HandleFunc("/products/{id:[0-9]+}/variants", adminCtrl.GetVariantsByProduct).Methods("GET")
-	adminRouter.HandleFunc("/variants", adminCtrl.GetAllVariants).Methods("GET")
-	adminRouter.HandleFunc("/products/{id:[0-9]+}/variants", adminCtrl.CreateVariant).Methods("POST")
-	adminRouter.HandleFunc("/products/{id:[0-9]+}/variants/{variantId:[0-9]+}", adminCtrl.EditVariant).Methods("PUT")
-	adminRouter.HandleFunc("/products/{id:[0-9]+}/variants/{variantId:[0-9]+}", adminCtrl.DeleteVariant).Methods("DELETE")
+	adminRouter.Handle("/products/{id:[0-9]+}/variants", can(service.PermCatalogRead, c.Products.GetVariantsByProduct)).Methods("GET")
+	adminRouter.Handle("/variants", can(service.PermCatalogRead, c.Products.GetAllVariants)).Methods("GET")
+	adminRouter.Handle("/products/{id:[0-9]+}/variants/{variantId:[0-9]+}", can(service.PermCatalogRead, c.Products.GetVariantDetail)).Methods("GET")
+	adminRouter.Handle("/products/{id:[0-9]+}/variants", can(service.PermCatalogManage, c.Products.CreateVariant)).Methods("POST")
+	adminRouter.Handle("/products/{id:[0-9]+}/variants/{variantId:[0-9]+}", can(service.PermCatalogManage, c.Products.EditVariant)).Methods("PUT")
+	adminRouter.Handle("/products/{id:[0-9]+}/variants/{variantId:[0-9]+}", can(service.PermCatalogManage, c.Products.DeleteVariant)).Methods("DELETE")
 
 	// Inventory Logs
-	adminRouter.HandleFunc("/inventory_logs", adminCtrl.GetAllInventoryLogs).Methods("GET")
-	adminRouter.HandleFunc("/inventory_logs/{id:[0-9]+}", adminCtrl.GetInventoryLogDetail).Methods("GET")
-	adminRouter.HandleFunc("/inventory_logs", adminCtrl.CreateInventoryLog).Methods("POST")
-	adminRouter.HandleFunc("/inventory_logs/{id:[0-9]+}", adminCtrl.EditInventoryLog).Methods("PUT")
-	adminRouter.HandleFunc("/inventory_logs/{id:[0-9]+}", adminCtrl.DeleteInventoryLog).Methods("DELETE")
-    // Orders
-	adminRouter.HandleFunc("/orders", adminCtrl.GetAllOrders).Methods("GET")
-	adminRouter.HandleFunc("/orders/{id:[0-9]+}", adminCtrl.GetOrderDetail).Methods("GET")
-	adminRouter.HandleFunc("/orders/{id:[0-9]+}/status", adminCtrl.UpdateOrderStatus).Methods("PATCH")
+	adminRouter.Handle("/inventory_logs", can(service.PermInventoryRead, c.Inventory.GetAllInventoryLogs)).Methods("GET")
+	adminRouter.Handle("/inventory_logs/{id:[0-9]+}", can(service.PermInventoryRead, c.Inventory.GetInventoryLogDetail)).Methods("GET")
+	adminRouter.Handle("/inventory_logs", can(service.PermInventoryManage, c.Inventory.CreateInventoryLog)).Methods("POST")
+	adminRouter.Handle("/inventory_logs/{id:[0-9]+}", can(service.PermInventoryManage, c.Inventory.EditInventoryLog)).Methods("PUT")
+	adminRouter.Handle("/inventory_logs/{id:[0-9]+}", can(service.PermInventoryManage, c.Inventory.DeleteInventoryLog)).Methods("DELETE")
+
+	// Orders
+	adminRouter.Handle("/orders", can(service.PermOrdersRead, c.Orders.GetAllOrders)).Methods("GET")
+	adminRouter.Handle("/orders/{id:[0-9]+}", can(service.PermOrdersRead, c.Orders.GetOrderDetail)).Methods("GET")
+	adminRouter.Handle("/orders/{id:[0-9]+}/status", can(service.PermOrdersUpdate, c.Orders.UpdateOrderStatus)).Methods("PATCH")
 
-	adminRouter.HandleFunc("/search", adminCtrl.SearchAll).Methods("GET")
-}
\ No newline at end of file
+	adminRouter.Handle("/search", can(service.PermSearch, c.Search.SearchAll)).Methods("GET")
+}
@@ -0,0 +1,213 @@
+package routes
+
+import (
+	adminCtrl "backend/internal/controllers/admin"
+	"backend/internal/models"
+	"backend/internal/repository"
+	adminRepo "backend/internal/repository/admin"
+	"backend/internal/service"
+	"backend/internal/testdb"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// newAdminRouter dựng /api/admin như cmd/main.go trên DB test đã seed role hệ thống,
+// trả về access token của 1 user cho mỗi role
+func newAdminRouter(t *testing.T) (*mux.Router, map[string]string, *gorm.DB) {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "test-secret")
+	db := testdb.Open(t)
+
+	roleRepo := adminRepo.NewRoleRepository(db)
+	if err := roleRepo.SeedRolesAndPermissions(); err != nil {
+		t.Fatalf("seed roles: %v", err)
+	}
+
+	r := mux.NewRouter()
+	SetupAdminRoutes(r, AdminControllers{
+		Users:      adminCtrl.NewUserController(adminRepo.NewUserRepository(db)),
+		Roles:      adminCtrl.NewRoleController(roleRepo),
+		Suppliers:  adminCtrl.NewSupplierController(adminRepo.NewSupplierRepository(db)),
+		Purchases:  adminCtrl.NewPurchaseController(adminRepo.NewPurchaseRepository(db)),
+		Categories: adminCtrl.NewCategoryController(adminRepo.NewCategoryRepository(db)),
+		Products:   adminCtrl.NewProductController(adminRepo.NewProductRepository(db)),
+		Inventory:  adminCtrl.NewInventoryController(adminRepo.NewInventoryRepository(db)),
+		Orders:     adminCtrl.NewOrderController(adminRepo.NewOrderRepository(db)),
+		Search:     adminCtrl.NewSearchController(adminRepo.NewSearchRepository(db)),
+	})
+
+	tokens := map[string]string{}
+	for _, role := range []string{"customer", "staff", "admin"} {
+		user := models.User{Username: role, Email: role + "@example.com", PasswordHash: "x", Role: role}
+		if err := db.Create(&user).Error; err != nil {
+			t.Fatalf("create %s: %v", role, err)
+		}
+		tokens[role] = login(t, &user)
+	}
+	return r, tokens, db
+}
+
+// login tạo access token giống lúc đăng nhập: kèm role_ids của user
+func login(t *testing.T, user *models.User) string {
+	t.Helper()
+	roleIDs, err := repository.GetUserRoleIDs(user)
+	if err != nil {
+		t.Fatalf("role ids: %v", err)
+	}
+	token, err := service.GenerateToken(user.ID, user.Role, roleIDs)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+	return token
+}
+
+func serve(r http.Handler, method, path, token string) int {
+	return request(r, method, path, token).Code
+}
+
+func request(r http.Handler, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// Mỗi ranh giới quyền: customer luôn 403, staff theo quyền mặc định, admin (PermAll) luôn qua
+func TestAdminRoutePermissions(t *testing.T) {
+	r, tokens, _ := newAdminRouter(t)
+
+	tests := []struct {
+		method, path string
+		perm         string
+		staff, admin int
+	}{
+		{"GET", "/api/admin/users", service.PermUsersRead, 200, 200},
+		{"GET", "/api/admin/logs", service.PermLogsRead, 200, 200},
+		{"GET", "/api/admin/roles", service.PermRolesManage, 403, 200},
+		{"GET", "/api/admin/permissions", service.PermRolesManage, 403, 200},
+		{"GET", "/api/admin/suppliers", service.PermSuppliersRead, 200, 200},
+		{"GET", "/api/admin/purchases", service.PermPurchasesRead, 200, 200},
+		{"GET", "/api/admin/categories", service.PermCatalogRead, 200, 200},
+		{"GET", "/api/admin/products", service.PermCatalogRead, 200, 200},
+		{"GET", "/api/admin/products/trash", service.PermCatalogRead, 200, 200},
+		{"GET", "/api/admin/variants", service.PermCatalogRead, 200, 200},
+		{"GET", "/api/admin/inventory_logs", service.PermInventoryRead, 200, 200},
+		{"GET", "/api/admin/orders", service.PermOrdersRead, 200, 200},
+		{"GET", "/api/admin/search?q=x", service.PermSearch, 200, 200},
+		// Qua được kiểm tra quyền nhưng bản ghi không tồn tại -> 404
+		{"DELETE", "/api/admin/products/999/purge", service.PermTrashPurge, 403, 404},
+		{"DELETE", "/api/admin/users/999/purge", service.PermTrashPurge, 403, 404},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			want := map[string]int{"customer": http.StatusForbidden, "staff": tt.staff, "admin": tt.admin}
+			for _, role := range []string{"customer", "staff", "admin"} {
+				if got := serve(r, tt.method, tt.path, tokens[role]); got != want[role] {
+					t.Errorf("%s (%s): status = %d, want %d", role, tt.perm, got, want[role])
+				}
+			}
+		})
+	}
+}
+
+func TestAdminRoutesRequireToken(t *testing.T) {
+	r, _, _ := newAdminRouter(t)
+
+	if got := serve(r, "GET", "/api/admin/products", ""); got != http.StatusUnauthorized {
+		t.Errorf("no token: status = %d, want 401", got)
+	}
+	if got := serve(r, "GET", "/api/admin/products", "not-a-jwt"); got != http.StatusUnauthorized {
+		t.Errorf("invalid token: status = %d, want 401", got)
+	}
+}
+
+// GET /api/admin/logs: logs:read chỉ thấy log của mình, logs:read_all (kể cả qua role tùy biến) thấy tất cả
+func TestLoginLogsScopedByPermission(t *testing.T) {
+	r, tokens, db := newAdminRouter(t)
+
+	var users []models.User
+	db.Order("id").Find(&users)
+	for _, u := range users {
+		if err := db.Create(&models.LoginLog{UserID: u.ID, Role: u.Role, Status: "success"}).Error; err != nil {
+			t.Fatalf("create log: %v", err)
+		}
+	}
+	var staff models.User
+	db.Where("role = ?", "staff").First(&staff)
+
+	logUsers := func(token string) []uint {
+		t.Helper()
+		w := request(r, "GET", "/api/admin/logs", token)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", w.Code)
+		}
+		var body struct {
+			Data []models.LoginLog `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		var ids []uint
+		for _, l := range body.Data {
+			ids = append(ids, l.UserID)
+		}
+		return ids
+	}
+
+	if ids := logUsers(tokens["staff"]); len(ids) != 1 || ids[0] != staff.ID {
+		t.Errorf("staff sees logs of users %v, want only %d", ids, staff.ID)
+	}
+	if ids := logUsers(tokens["admin"]); len(ids) != len(users) {
+		t.Errorf("admin sees %d logs, want %d", len(ids), len(users))
+	}
+
+	roles := adminRepo.NewRoleRepository(db)
+	auditor, err := roles.CreateRole("auditor", "", []string{service.PermLogsReadAll})
+	if err != nil {
+		t.Fatalf("create role: %v", err)
+	}
+	if err := roles.SetUserRoles(staff.ID, []uint{auditor.ID}); err != nil {
+		t.Fatalf("set roles: %v", err)
+	}
+	if ids := logUsers(login(t, &staff)); len(ids) != len(users) {
+		t.Errorf("staff with auditor role sees %d logs, want %d", len(ids), len(users))
+	}
+}
+
+// staff phụ trách order lấy từ JWT, staff_id trong body bị bỏ qua
+func TestUpdateOrderStatusAssignsCaller(t *testing.T) {
+	r, tokens, db := newAdminRouter(t)
+	var staff, customer models.User
+	db.Where("username = ?", "staff").First(&staff)
+	db.Where("username = ?", "customer").First(&customer)
+	order := models.Order{CustomerID: customer.ID}
+	if err := db.Omit("Customer", "Staff").Create(&order).Error; err != nil {
+		t.Fatalf("create order: %v", err)
+	}
+
+	body := `{"status":"confirmed","staff_id":` + strconv.Itoa(int(customer.ID)) + `}`
+	req := httptest.NewRequest("PATCH", "/api/admin/orders/"+strconv.Itoa(int(order.ID))+"/status", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+tokens["staff"])
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body)
+	}
+
+	if err := db.First(&order, order.ID).Error; err != nil {
+		t.Fatalf("reload order: %v", err)
+	}
+	if order.Status != service.OrderConfirmed || order.StaffID == nil || *order.StaffID != staff.ID {
+		t.Errorf("order = %s staff %v, want %s staff %d", order.Status, order.StaffID, service.OrderConfirmed, staff.ID)
+	}
+}
@@ -0,0 +1,41 @@
+package routes
+
+import (
+	customerCtrl "backend/internal/controllers/customer"
+	"backend/internal/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// CustomerControllers gom các controller "của tôi" đã được khởi tạo sẵn dependency (xem cmd/main.go)
+type CustomerControllers struct {
+	Orders *customerCtrl.OrderController
+}
+
+// SetupCustomerRoutes đăng ký các API "của tôi" cho user đã đăng nhập
+func SetupCustomerRoutes(r *mux.Router, c CustomerControllers) {
+	// Link xác nhận email mới (mở từ email, không có JWT)
+	r.HandleFunc("/api/me/email/confirm", customerCtrl.ConfirmEmailChange).Methods("GET")
+
+	meRouter := r.PathPrefix("/api/me").Subrouter()
+	meRouter.Use(middlewares.JWTMiddleware)
+
+	// Profile
+	meRouter.HandleFunc("", customerCtrl.GetProfile).Methods("GET")
+	meRouter.HandleFunc("", customerCtrl.UpdateProfile).Methods("PATCH")
+	meRouter.HandleFunc("/password", customerCtrl.ChangePassword).Methods("POST")
+	meRouter.HandleFunc("/email", customerCtrl.RequestEmailChange).Methods("POST")
+
+	// Addresses
+	meRouter.HandleFunc("/addresses", customerCtrl.GetMyAddresses).Methods("GET")
+	meRouter.HandleFunc("/addresses", customerCtrl.CreateMyAddress).Methods("POST")
+	meRouter.HandleFunc("/addresses/{id:[0-9]+}", customerCtrl.GetMyAddress).Methods("GET")
+	meRouter.HandleFunc("/addresses/{id:[0-9]+}", customerCtrl.UpdateMyAddress).Methods("PUT")
+	meRouter.HandleFunc("/addresses/{id:[0-9]+}", customerCtrl.DeleteMyAddress).Methods("DELETE")
+	meRouter.HandleFunc("/addresses/{id:[0-9]+}/default", customerCtrl.SetDefaultAddress).Methods("POST")
+
+	// Orders
+	meRouter.HandleFunc("/orders", c.Orders.GetMyOrders).Methods("GET")
+	meRouter.HandleFunc("/orders/{id:[0-9]+}", c.Orders.GetMyOrderDetail).Methods("GET")
+	meRouter.HandleFunc("/orders/{id:[0-9]+}/cancel", c.Orders.CancelMyOrder).Methods("POST")
+}
@@ -2,6 +2,9 @@ package routes
 
 import (
 	"backend/internal/controllers"
+	"backend/internal/middlewares"
+	"net/http"
+
 	"github.com/gorilla/mux"
 )
 
@@ -11,5 +14,19 @@ func SetupRoutes(r *mux.Router) {
 	auth := api.PathPrefix("/auth").Subrouter()
 	auth.HandleFunc("/register", controllers.RegisterHandler).Methods("POST")
 	auth.HandleFunc("/confirm", controllers.ConfirmRegisterHandler).Methods("GET")
+	auth.HandleFunc("/resend-confirmation", controllers.ResendConfirmationHandler).Methods("POST")
 	auth.HandleFunc("/login", controllers.LoginHandler).Methods("POST")
-}
\ No newline at end of file
+	auth.HandleFunc("/refresh", controllers.RefreshHandler).Methods("POST")
+	auth.HandleFunc("/forgot-password", controllers.ForgotPasswordHandler).Methods("POST")
+	auth.HandleFunc("/reset-password", controllers.ResetPasswordHandler).Methods("POST")
+	auth.Handle("/logout", middlewares.JWTMiddleware(http.HandlerFunc(controllers.LogoutHandler))).Methods("POST")
+	auth.Handle("/logout-all", middlewares.JWTMiddleware(http.HandlerFunc(controllers.LogoutAllHandler))).Methods("POST")
+
+	// Two-factor (TOTP): verify dùng partial token từ /login; setup/enable dùng được cả 2 loại token
+	twoFactor := auth.PathPrefix("/2fa").Subrouter()
+	twoFactor.Handle("/verify", middlewares.MFAMiddleware(false)(http.HandlerFunc(controllers.TwoFactorVerifyHandler))).Methods("POST")
+	twoFactor.Handle("/setup", middlewares.MFAMiddleware(true)(http.HandlerFunc(controllers.TwoFactorSetupHandler))).Methods("POST")
+	twoFactor.Handle("/enable", middlewares.MFAMiddleware(true)(http.HandlerFunc(controllers.TwoFactorEnableHandler))).Methods("POST")
+	twoFactor.Handle("/disable", middlewares.JWTMiddleware(http.HandlerFunc(controllers.TwoFactorDisableHandler))).Methods("POST")
+	twoFactor.Handle("/backup-codes", middlewares.JWTMiddleware(http.HandlerFunc(controllers.TwoFactorBackupCodesHandler))).Methods("POST")
+}
@@ -0,0 +1,32 @@
+package routes
+
+import (
+	shopCtrl "backend/internal/controllers/shop"
+	"backend/internal/middlewares"
+
+	"github.com/gorilla/mux"
+)
+
+// SetupShopRoutes đăng ký các API public cho storefront (không cần JWT)
+func SetupShopRoutes(r *mux.Router) {
+	shopRouter := r.PathPrefix("/api/shop").Subrouter()
+
+	// Catalog
+	shopRouter.HandleFunc("/products", shopCtrl.GetCatalogProducts).Methods("GET")
+	shopRouter.HandleFunc("/products/{id:[0-9]+}", shopCtrl.GetCatalogProductDetail).Methods("GET")
+	shopRouter.HandleFunc("/categories", shopCtrl.GetCatalogCategories).Methods("GET")
+
+	// Cart: customer (JWT) hoặc khách vãng lai (X-Cart-Token)
+	cartRouter := shopRouter.PathPrefix("/cart").Subrouter()
+	cartRouter.Use(middlewares.OptionalJWTMiddleware)
+	cartRouter.HandleFunc("", shopCtrl.GetCart).Methods("GET")
+	cartRouter.HandleFunc("", shopCtrl.ClearCart).Methods("DELETE")
+	cartRouter.HandleFunc("/items", shopCtrl.AddCartItem).Methods("POST")
+	cartRouter.HandleFunc("/items/{itemId:[0-9]+}", shopCtrl.UpdateCartItem).Methods("PUT")
+	cartRouter.HandleFunc("/items/{itemId:[0-9]+}", shopCtrl.RemoveCartItem).Methods("DELETE")
+
+	// Checkout: bắt buộc đăng nhập
+	checkoutRouter := shopRouter.PathPrefix("/checkout").Subrouter()
+	checkoutRouter.Use(middlewares.JWTMiddleware)
+	checkoutRouter.HandleFunc("", shopCtrl.Checkout).Methods("POST")
+}
@@ -0,0 +1,64 @@
+package routes
+
+import (
+	"backend/internal/models"
+	"backend/internal/repository"
+	"backend/internal/service"
+	"backend/internal/testdb"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Enrol 2FA bằng partial token cũng bị khóa lũy tiến như /2fa/verify, không thử mã được nữa
+func TestTwoFactorEnableRespectsAccountLockout(t *testing.T) {
+	t.Setenv("JWT_SECRET", "test-secret")
+	db := testdb.Open(t)
+	r := mux.NewRouter()
+	SetupRoutes(r)
+
+	user := models.User{Username: "admin", Email: "admin@example.com", PasswordHash: "x", Role: "admin"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	secret, err := service.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("secret: %v", err)
+	}
+	if err := repository.SaveTOTPSecret(user.ID, secret); err != nil {
+		t.Fatalf("save secret: %v", err)
+	}
+	token, err := service.GenerateMFAToken(user.ID, user.Role)
+	if err != nil {
+		t.Fatalf("token: %v", err)
+	}
+
+	enable := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/api/auth/2fa/enable", strings.NewReader(`{"code":"000000"}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// Mã sai được ghi log và tính vào số lần sai liên tiếp
+	if w := enable(); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong code: status = %d, want 401", w.Code)
+	}
+	failures, _, err := repository.GetConsecutiveFailedLogins(user.ID)
+	if err != nil || failures != 1 {
+		t.Fatalf("failures = %d (%v), want 1", failures, err)
+	}
+
+	for i := 0; i < 4; i++ {
+		db.Create(&models.LoginLog{UserID: user.ID, Status: "failed", CreatedAt: time.Now()})
+	}
+	w := enable()
+	if w.Code != http.StatusLocked || w.Header().Get("Retry-After") == "" {
+		t.Errorf("locked account: status = %d, Retry-After = %q, want 423 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+}
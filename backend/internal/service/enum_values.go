@@ -0,0 +1,27 @@
+package service
+
+// Các cột dạng enum lưu VARCHAR + CHECK constraint (chạy được trên MySQL/Postgres/SQLite);
+// giá trị hợp lệ được kiểm tra ở đây trước khi ghi DB để trả lỗi 400 thay vì lỗi constraint.
+
+var CategoryGroups = []string{"Đồ nam", "Đồ nữ", "Đồ thể thao", "Trẻ em", "Phụ kiện"}
+
+var PaymentMethods = []string{"cod", "online"}
+
+var InventoryChangeTypes = []string{"import", "sale", "return", "adjust"}
+
+func IsValidCategoryGroup(group string) bool {
+	return contains(CategoryGroups, group)
+}
+
+func IsValidPaymentMethod(method string) bool {
+	return contains(PaymentMethods, method)
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
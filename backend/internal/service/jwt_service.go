@@ -1,24 +1,66 @@
 package service
 
 import (
+	"backend/internal/utils"
 	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+const (
+	// Access token sống ngắn; phiên dài hạn được duy trì bằng refresh token
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	// Partial token sau bước mật khẩu, chỉ dùng cho các API 2FA
+	MFATokenTTL = 5 * time.Minute
+
+	StageMFA = "mfa"
+)
+
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Role   string `json:"role"`
+	UserID  uint   `json:"user_id"`
+	Role    string `json:"role"`
+	RoleIDs []uint `json:"role_ids"`
+	// Stage = StageMFA: partial token, chưa xác thực bước 2
+	Stage string `json:"stage,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, role string) (string, error) {
+func GenerateToken(userID uint, role string, roleIDs []uint) (string, error) {
+	// jti dùng cho danh sách thu hồi (logout)
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
+	claims := Claims{
+		UserID:  userID,
+		Role:    role,
+		RoleIDs: roleIDs,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(os.Getenv("JWT_SECRET")))
+}
+
+// GenerateMFAToken tạo partial token sau khi đúng mật khẩu, chờ xác thực TOTP
+func GenerateMFAToken(userID uint, role string) (string, error) {
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", err
+	}
 	claims := Claims{
 		UserID: userID,
 		Role:   role,
+		Stage:  StageMFA,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(MFATokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
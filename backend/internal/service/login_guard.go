@@ -0,0 +1,43 @@
+package service
+
+import "time"
+
+// Cửa sổ đếm số lần đăng nhập sai theo IP
+const IPFailureWindow = 15 * time.Minute
+
+// AccountLockoutDuration: khóa lũy tiến theo số lần sai liên tiếp của 1 tài khoản
+// (đếm từ lần đăng nhập thành công / mở khóa / đặt lại mật khẩu gần nhất)
+func AccountLockoutDuration(failures int) time.Duration {
+	switch {
+	case failures >= 15:
+		return time.Hour
+	case failures >= 10:
+		return 15 * time.Minute
+	case failures >= 5:
+		return time.Minute
+	}
+	return 0
+}
+
+// IPLockoutDuration: chặn IP khi có quá nhiều lần sai trong IPFailureWindow
+func IPLockoutDuration(failures int) time.Duration {
+	switch {
+	case failures >= 50:
+		return time.Hour
+	case failures >= 20:
+		return IPFailureWindow
+	}
+	return 0
+}
+
+// LockedUntil trả về thời điểm hết khóa (zero nếu không bị khóa)
+func LockedUntil(lastFailure time.Time, d time.Duration) time.Time {
+	if d == 0 || lastFailure.IsZero() {
+		return time.Time{}
+	}
+	until := lastFailure.Add(d)
+	if time.Now().After(until) {
+		return time.Time{}
+	}
+	return until
+}
@@ -7,7 +7,8 @@ import (
 	"os"
 )
 
-func SendConfirmationEmail(toEmail, confirmLink string) error {
+// sendMail gửi email HTML qua SMTP cấu hình trong .env
+func sendMail(toEmail, subject, body string) error {
 	from := os.Getenv("EMAIL_USER")
 	pass := os.Getenv("EMAIL_PASS")
 	host := os.Getenv("EMAIL_HOST")
@@ -15,12 +16,9 @@ func SendConfirmationEmail(toEmail, confirmLink string) error {
 
 	auth := smtp.PlainAuth("", from, pass, host)
 
-	subject := "Subject: ✅ Xác nhận đăng ký tài khoản\n"
-	body := fmt.Sprintf(`<html>...<a href="%s">Xác nhận</a>...</html>`, confirmLink)
-
 	msg := []byte("From: " + from + "\n" +
 		"To: " + toEmail + "\n" +
-		subject +
+		"Subject: " + subject + "\n" +
 		"MIME-Version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n" + body)
 
 	addr := fmt.Sprintf("%s:%s", host, port)
@@ -31,3 +29,25 @@ func SendConfirmationEmail(toEmail, confirmLink string) error {
 	}
 	return nil
 }
+
+func SendConfirmationEmail(toEmail, confirmLink string) error {
+	subject := "✅ Xác nhận đăng ký tài khoản"
+	body := fmt.Sprintf(`<html>...<a href="%s">Xác nhận</a>...</html>`, confirmLink)
+	return sendMail(toEmail, subject, body)
+}
+
+func SendPasswordResetEmail(toEmail, resetLink string) error {
+	subject := "🔑 Đặt lại mật khẩu"
+	body := fmt.Sprintf(`<html><p>Bạn vừa yêu cầu đặt lại mật khẩu. Link có hiệu lực trong 30 phút:</p>`+
+		`<a href="%s">Đặt lại mật khẩu</a>`+
+		`<p>Nếu không phải bạn, hãy bỏ qua email này.</p></html>`, resetLink)
+	return sendMail(toEmail, subject, body)
+}
+
+func SendEmailChangeEmail(toEmail, confirmLink string) error {
+	subject := "📧 Xác nhận địa chỉ email mới"
+	body := fmt.Sprintf(`<html><p>Bạn vừa yêu cầu đổi email tài khoản sang địa chỉ này.</p>`+
+		`<a href="%s">Xác nhận email mới</a>`+
+		`<p>Nếu không phải bạn, hãy bỏ qua email này.</p></html>`, confirmLink)
+	return sendMail(toEmail, subject, body)
+}
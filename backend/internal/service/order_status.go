@@ -0,0 +1,50 @@
+package service
+
+import "backend/internal/apperr"
+
+const (
+	OrderPending   = "pending"
+	OrderConfirmed = "confirmed"
+	OrderShipped   = "shipped"
+	OrderCompleted = "completed"
+	OrderCancelled = "cancelled"
+)
+
+// OrderStatuses: mọi giá trị hợp lệ của Order.Status
+var OrderStatuses = []string{OrderPending, OrderConfirmed, OrderShipped, OrderCompleted, OrderCancelled}
+
+// LiveOrderStatuses: đơn chưa kết thúc (chưa hoàn tất / chưa hủy)
+var LiveOrderStatuses = []string{OrderPending, OrderConfirmed, OrderShipped}
+
+var (
+	ErrInvalidOrderStatus = apperr.Validation("invalid order status").WithCode("invalid_order_status")
+	ErrIllegalTransition  = apperr.Conflict("illegal order status transition").WithCode("illegal_transition")
+)
+
+// orderTransitions: pending → confirmed → shipped → completed, chỉ được hủy trước khi giao
+var orderTransitions = map[string][]string{
+	OrderPending:   {OrderConfirmed, OrderCancelled},
+	OrderConfirmed: {OrderShipped, OrderCancelled},
+	OrderShipped:   {OrderCompleted},
+	OrderCompleted: {},
+	OrderCancelled: {},
+}
+
+// IsValidOrderStatus kiểm tra status có thuộc enum của Order không
+func IsValidOrderStatus(status string) bool {
+	_, ok := orderTransitions[status]
+	return ok
+}
+
+// ValidateOrderTransition trả lỗi nếu không được phép chuyển from → to
+func ValidateOrderTransition(from, to string) error {
+	if !IsValidOrderStatus(to) {
+		return ErrInvalidOrderStatus.WithDetails(map[string]string{"status": to})
+	}
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return nil
+		}
+	}
+	return ErrIllegalTransition.WithDetails(map[string]string{"from": from, "to": to})
+}
@@ -0,0 +1,81 @@
+package service
+
+// Capabilities dùng để khai báo quyền cho từng route admin
+const (
+	PermAll = "*"
+
+	PermUsersRead   = "users:read"
+	PermUsersManage = "users:manage"
+	PermLogsRead    = "logs:read"
+	// Xem log đăng nhập của mọi user; chỉ có logs:read thì chỉ xem log của chính mình
+	PermLogsReadAll = "logs:read_all"
+
+	PermSuppliersRead   = "suppliers:read"
+	PermSuppliersManage = "suppliers:manage"
+
+	PermPurchasesRead   = "purchases:read"
+	PermPurchasesCreate = "purchases:create"
+	PermPurchasesManage = "purchases:manage"
+
+	PermCatalogRead   = "catalog:read"
+	PermCatalogManage = "catalog:manage"
+
+	PermInventoryRead   = "inventory:read"
+	PermInventoryManage = "inventory:manage"
+
+	PermOrdersRead   = "orders:read"
+	PermOrdersUpdate = "orders:update"
+
+	PermSearch      = "search:read"
+	PermRolesManage = "roles:manage"
+
+	// Xóa vĩnh viễn bản ghi trong thùng rác, chỉ admin (PermAll) có mặc định
+	PermTrashPurge = "trash:purge"
+)
+
+// AllPermissions là danh mục capability được seed vào bảng permissions
+var AllPermissions = []string{
+	PermAll,
+	PermUsersRead, PermUsersManage, PermLogsRead, PermLogsReadAll,
+	PermSuppliersRead, PermSuppliersManage,
+	PermPurchasesRead, PermPurchasesCreate, PermPurchasesManage,
+	PermCatalogRead, PermCatalogManage,
+	PermInventoryRead, PermInventoryManage,
+	PermOrdersRead, PermOrdersUpdate,
+	PermSearch, PermRolesManage,
+	PermTrashPurge,
+}
+
+// DefaultRolePermissions: quyền mặc định của các role hệ thống, chỉ dùng khi seed lần đầu.
+// Sau đó quyền được quản lý trong DB qua /api/admin/roles.
+var DefaultRolePermissions = map[string][]string{
+	"admin": {PermAll},
+	"staff": {
+		PermUsersRead, PermLogsRead,
+		PermSuppliersRead, PermSuppliersManage,
+		PermPurchasesRead, PermPurchasesCreate, PermPurchasesManage,
+		PermCatalogRead, PermCatalogManage,
+		PermInventoryRead, PermInventoryManage,
+		PermOrdersRead, PermOrdersUpdate,
+		PermSearch,
+	},
+	// customer không có quyền nào trên /api/admin
+	"customer": {},
+}
+
+// PermissionSet là tập capability đã resolve của 1 user
+type PermissionSet map[string]bool
+
+// NewPermissionSet tạo PermissionSet từ danh sách code
+func NewPermissionSet(codes []string) PermissionSet {
+	set := make(PermissionSet, len(codes))
+	for _, c := range codes {
+		set[c] = true
+	}
+	return set
+}
+
+// Has kiểm tra capability perm (PermAll cho phép mọi thứ)
+func (s PermissionSet) Has(perm string) bool {
+	return s[PermAll] || s[perm]
+}
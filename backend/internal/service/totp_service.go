@@ -0,0 +1,110 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// TOTP theo RFC 6238: HMAC-SHA1, 6 chữ số, bước 30 giây
+const (
+	totpDigits = 6
+	totpPeriod = 30
+	// Chấp nhận lệch ±1 bước để bù sai lệch đồng hồ
+	totpSkew = 1
+
+	BackupCodeCount = 10
+)
+
+// mfaRequiredRoles: role bắt buộc bật 2FA, các role khác tùy chọn
+var mfaRequiredRoles = map[string]bool{
+	"admin": true,
+}
+
+// IsMFARequired cho biết role có bắt buộc 2FA không
+func IsMFARequired(role string) bool {
+	return mfaRequiredRoles[role]
+}
+
+var base32NoPad = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret tạo secret 160 bit, mã hóa base32 (không padding) cho app Authenticator
+func GenerateTOTPSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base32NoPad.EncodeToString(b), nil
+}
+
+// TOTPProvisioningURI trả về otpauth:// URI để FE render QR code
+func TOTPProvisioningURI(secret, account string) string {
+	issuer := os.Getenv("APP_NAME")
+	if issuer == "" {
+		issuer = "Clothing Shop"
+	}
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// ValidateTOTP kiểm tra code tại thời điểm at, trả về time step khớp (để chống dùng lại)
+func ValidateTOTP(secret, code string, at time.Time) (int64, bool) {
+	key, err := base32NoPad.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	step := at.Unix() / totpPeriod
+	for i := -totpSkew; i <= totpSkew; i++ {
+		candidate := totpCode(key, step+int64(i))
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(code)) == 1 {
+			return step + int64(i), true
+		}
+	}
+	return 0, false
+}
+
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// GenerateBackupCodes tạo các mã dự phòng dạng xxxx-xxxx (chỉ hiển thị 1 lần)
+func GenerateBackupCodes() ([]string, error) {
+	codes := make([]string, 0, BackupCodeCount)
+	for i := 0; i < BackupCodeCount; i++ {
+		b := make([]byte, 5)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		s := strings.ToLower(base32NoPad.EncodeToString(b))
+		codes = append(codes, s[:4]+"-"+s[4:])
+	}
+	return codes, nil
+}
+
+// NormalizeBackupCode bỏ khoảng trắng / gạch nối, đưa về chữ thường trước khi hash
+func NormalizeBackupCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,48 @@
+// Package testdb mở DB SQLite trong bộ nhớ đã chạy đủ migration cho các test.
+package testdb
+
+import (
+	"backend/configs"
+	"backend/migrations"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Open tạo DB SQLite ":memory:" mới (qua configs.Dialector + configs.Open như khi chạy thật),
+// chạy migrations.Up và gán vào configs.DB cho các repository còn dùng biến toàn cục.
+// DB và configs.DB cũ được trả lại khi test kết thúc.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	t.Setenv("DB_NAME", ":memory:")
+
+	dialector, err := configs.Dialector("sqlite")
+	if err != nil {
+		t.Fatalf("testdb: %v", err)
+	}
+	db, err := configs.Open(dialector)
+	if err != nil {
+		t.Fatalf("testdb: open: %v", err)
+	}
+	db.Logger = logger.Default.LogMode(logger.Silent)
+
+	// Mỗi kết nối ":memory:" là 1 DB riêng, nên chỉ giữ đúng 1 kết nối
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatalf("testdb: %v", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if _, err := migrations.Up(db, 0); err != nil {
+		t.Fatalf("testdb: migrate: %v", err)
+	}
+
+	prev := configs.DB
+	configs.DB = db
+	t.Cleanup(func() {
+		configs.DB = prev
+		sqlDB.Close()
+	})
+	return db
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SetETag gắn ETag theo version của bản ghi (optimistic concurrency)
+func SetETag(w http.ResponseWriter, version uint) {
+	w.Header().Set("ETag", `"`+strconv.FormatUint(uint64(version), 10)+`"`)
+}
+
+// IfMatchVersion đọc version từ header If-Match (chấp nhận cả dạng weak W/"3").
+// present = false khi client không gửi header; ok = false khi header không phải 1 version hợp lệ.
+func IfMatchVersion(r *http.Request) (version uint, present bool, ok bool) {
+	raw := strings.TrimSpace(r.Header.Get("If-Match"))
+	if raw == "" {
+		return 0, false, false
+	}
+	raw = strings.TrimPrefix(raw, "W/")
+	raw = strings.Trim(raw, `"`)
+	v, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil || v == 0 {
+		return 0, true, false
+	}
+	return uint(v), true, true
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+var (
+	trustedOnce    sync.Once
+	trustedProxies []*net.IPNet
+)
+
+// loadTrustedProxies đọc TRUSTED_PROXIES (danh sách IP hoặc CIDR, cách nhau bởi dấu phẩy)
+func loadTrustedProxies() {
+	for _, item := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if !strings.Contains(item, "/") {
+			if strings.Contains(item, ":") {
+				item += "/128"
+			} else {
+				item += "/32"
+			}
+		}
+		if _, ipNet, err := net.ParseCIDR(item); err == nil {
+			trustedProxies = append(trustedProxies, ipNet)
+		}
+	}
+}
+
+func isTrustedProxy(ip string) bool {
+	trustedOnce.Do(loadTrustedProxies)
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range trustedProxies {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP trả về IP thật của client. X-Forwarded-For chỉ được tin khi request đi qua
+// proxy nằm trong TRUSTED_PROXIES; đọc từ phải sang trái và bỏ qua các proxy tin cậy.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if !isTrustedProxy(host) {
+		return host
+	}
+
+	parts := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(parts) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(parts[i])
+		if net.ParseIP(ip) == nil {
+			break
+		}
+		if !isTrustedProxy(ip) {
+			return ip
+		}
+		host = ip
+	}
+	return host
+}
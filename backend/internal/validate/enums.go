@@ -0,0 +1,11 @@
+package validate
+
+import "backend/internal/service"
+
+// enums: các tập giá trị dùng với rule "enum=<tên>", lấy từ service để không lặp lại danh sách
+var enums = map[string][]string{
+	"category_group":   service.CategoryGroups,
+	"payment_method":   service.PaymentMethods,
+	"order_status":     service.OrderStatuses,
+	"inventory_change": service.InventoryChangeTypes,
+}
@@ -0,0 +1,281 @@
+// Package validate kiểm tra request DTO theo tag `validate:"..."` và trả lỗi theo từng field.
+//
+// Các rule (phân cách bằng dấu phẩy):
+//
+//	required     field phải có giá trị (string không rỗng sau khi trim, số khác 0, slice không rỗng, con trỏ khác nil)
+//	omitempty    bỏ qua các rule còn lại nếu field rỗng
+//	notblank     string không được rỗng (sau khi trim); khác required ở chỗ con trỏ nil vẫn hợp lệ
+//	min=N, max=N số: giá trị; string: số ký tự; slice: số phần tử
+//	gt=N         số phải lớn hơn N
+//	email        địa chỉ email hợp lệ
+//	oneof=a|b    giá trị nằm trong danh sách
+//	enum=name    giá trị nằm trong tập enum dùng chung (xem enums.go)
+//
+// Field con trỏ bằng nil chỉ bị kiểm tra rule required. Struct lồng nhau và slice of struct
+// được kiểm tra đệ quy, tên field trong lỗi theo tag json (vd "items[0].quantity").
+package validate
+
+import (
+	"backend/internal/apperr"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// FieldError: 1 lỗi của 1 field trong request
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ErrValidation: request sai rule, Details là []FieldError
+var ErrValidation = apperr.Validation("Validation failed")
+
+// DecodeJSON đọc body JSON vào dst rồi kiểm tra theo tag validate.
+// Body sai cú pháp -> 400, field sai kiểu hoặc sai rule -> 422 kèm danh sách field lỗi.
+func DecodeJSON(r *http.Request, dst interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return ErrValidation.WithDetails([]FieldError{{
+				Field:   typeErr.Field,
+				Rule:    "type",
+				Message: "must be a " + typeName(typeErr.Type),
+			}})
+		}
+		return apperr.BadRequest("Invalid input").Wrap(err)
+	}
+	return Struct(dst)
+}
+
+// Struct kiểm tra v (struct hoặc con trỏ tới struct), trả nil nếu hợp lệ
+func Struct(v interface{}) error {
+	var errs []FieldError
+	walk(reflect.ValueOf(v), "", &errs)
+	if len(errs) > 0 {
+		return ErrValidation.WithDetails(errs)
+	}
+	return nil
+}
+
+func walk(v reflect.Value, prefix string, errs *[]FieldError) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !f.Anonymous {
+				continue
+			}
+			name, skip := jsonName(f)
+			if skip {
+				continue
+			}
+			fv := v.Field(i)
+			// Struct nhúng không có tag json: field được gộp lên như encoding/json
+			if f.Anonymous && f.Tag.Get("json") == "" {
+				walk(fv, prefix, errs)
+				continue
+			}
+			path := name
+			if prefix != "" {
+				path = prefix + "." + name
+			}
+			if checkField(fv, f.Tag.Get("validate"), path, errs) {
+				walk(fv, path, errs)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			walk(v.Index(i), prefix+"["+strconv.Itoa(i)+"]", errs)
+		}
+	}
+}
+
+// checkField áp dụng các rule của 1 field; trả false nếu không cần kiểm tra sâu hơn
+func checkField(v reflect.Value, tag, path string, errs *[]FieldError) bool {
+	if tag == "" || tag == "-" {
+		return true
+	}
+	rules := strings.Split(tag, ",")
+
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			if hasRule(rules, "required") {
+				*errs = append(*errs, FieldError{path, "required", "is required"})
+			}
+			return false
+		}
+		v = v.Elem()
+	}
+
+	empty := isEmpty(v)
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch name {
+		case "omitempty":
+			if empty {
+				return false
+			}
+		case "required":
+			if empty {
+				*errs = append(*errs, FieldError{path, name, "is required"})
+				return false
+			}
+		default:
+			check, ok := rulesByName[name]
+			if !ok {
+				panic("validate: unknown rule " + strconv.Quote(name))
+			}
+			if msg := check(v, param); msg != "" {
+				*errs = append(*errs, FieldError{path, name, msg})
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// rulesByName: mỗi rule trả message lỗi, "" nếu hợp lệ
+var rulesByName = map[string]func(v reflect.Value, param string) string{
+	"notblank": func(v reflect.Value, _ string) string {
+		if strings.TrimSpace(v.String()) == "" {
+			return "cannot be blank"
+		}
+		return ""
+	},
+	"min": func(v reflect.Value, param string) string {
+		return compare(v, param, "at least", func(a, b float64) bool { return a >= b })
+	},
+	"max": func(v reflect.Value, param string) string {
+		return compare(v, param, "at most", func(a, b float64) bool { return a <= b })
+	},
+	"gt": func(v reflect.Value, param string) string {
+		return compare(v, param, "greater than", func(a, b float64) bool { return a > b })
+	},
+	"email": func(v reflect.Value, _ string) string {
+		s := strings.TrimSpace(v.String())
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be a valid email address"
+		}
+		return ""
+	},
+	"oneof": func(v reflect.Value, param string) string {
+		return inList(v, strings.Split(param, "|"))
+	},
+	"enum": func(v reflect.Value, param string) string {
+		values, ok := enums[param]
+		if !ok {
+			panic("validate: unknown enum " + strconv.Quote(param))
+		}
+		return inList(v, values)
+	},
+}
+
+// compare so sánh giá trị số, độ dài string hoặc số phần tử slice với param
+func compare(v reflect.Value, param, word string, ok func(a, b float64) bool) string {
+	limit, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic("validate: invalid number " + strconv.Quote(param))
+	}
+	var n float64
+	unit := ""
+	switch v.Kind() {
+	case reflect.String:
+		n, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		panic("validate: cannot compare " + v.Kind().String())
+	}
+	if ok(n, limit) {
+		return ""
+	}
+	if unit != "" && word != "greater than" {
+		return fmt.Sprintf("must have %s %s%s", word, param, unit)
+	}
+	return fmt.Sprintf("must be %s %s", word, param)
+}
+
+func inList(v reflect.Value, values []string) string {
+	s := fmt.Sprint(v)
+	for _, x := range values {
+		if x == s {
+			return ""
+		}
+	}
+	return "must be one of: " + strings.Join(values, ", ")
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+func hasRule(rules []string, name string) bool {
+	for _, r := range rules {
+		if strings.TrimSpace(r) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonName lấy tên field theo tag json (skip = true nếu tag là "-")
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	if name, _, _ := strings.Cut(tag, ","); name != "" {
+		return name, false
+	}
+	return f.Name, false
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	default:
+		return t.Kind().String()
+	}
+}
@@ -0,0 +1,161 @@
+package validate
+
+import (
+	"backend/internal/apperr"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type address struct {
+	Street string `json:"street" validate:"required,max=10"`
+}
+
+type item struct {
+	VariantID uint `json:"variant_id" validate:"required"`
+	Quantity  int  `json:"quantity" validate:"min=1,max=5"`
+}
+
+type embedded struct {
+	Note string `json:"note" validate:"max=3"`
+}
+
+type sample struct {
+	Name     string   `json:"name" validate:"required,max=5"`
+	Nick     *string  `json:"nick" validate:"notblank"`
+	Email    string   `json:"email" validate:"omitempty,email"`
+	Price    float64  `json:"price" validate:"gt=0"`
+	Status   string   `json:"status" validate:"omitempty,enum=order_status"`
+	Size     string   `json:"size" validate:"omitempty,oneof=S|M|L"`
+	Tags     []string `json:"tags" validate:"max=2"`
+	Address  *address `json:"address"`
+	Items    []item   `json:"items" validate:"required"`
+	Internal string   `json:"-" validate:"required"`
+	embedded
+}
+
+func valid() sample {
+	return sample{Name: "Bob", Price: 1, Items: []item{{VariantID: 1, Quantity: 1}}}
+}
+
+func TestStruct(t *testing.T) {
+	blank := "  "
+	tests := []struct {
+		name   string
+		modify func(s *sample)
+		want   []FieldError
+	}{
+		{"valid", func(s *sample) {}, nil},
+		{"required string", func(s *sample) { s.Name = "  " },
+			[]FieldError{{"name", "required", "is required"}}},
+		{"required slice", func(s *sample) { s.Items = nil },
+			[]FieldError{{"items", "required", "is required"}}},
+		{"max string counts runes", func(s *sample) { s.Name = "Phương" },
+			[]FieldError{{"name", "max", "must have at most 5 characters"}}},
+		{"max slice", func(s *sample) { s.Tags = []string{"a", "b", "c"} },
+			[]FieldError{{"tags", "max", "must have at most 2 items"}}},
+		{"gt", func(s *sample) { s.Price = 0 },
+			[]FieldError{{"price", "gt", "must be greater than 0"}}},
+		{"omitempty skips empty value", func(s *sample) { s.Email = "" }, nil},
+		{"email", func(s *sample) { s.Email = "Bob <bob@example.com>" },
+			[]FieldError{{"email", "email", "must be a valid email address"}}},
+		{"valid email", func(s *sample) { s.Email = "bob@example.com" }, nil},
+		{"enum", func(s *sample) { s.Status = "lost" },
+			[]FieldError{{"status", "enum", "must be one of: pending, confirmed, shipped, completed, cancelled"}}},
+		{"oneof", func(s *sample) { s.Size = "XL" },
+			[]FieldError{{"size", "oneof", "must be one of: S, M, L"}}},
+		{"notblank nil pointer", func(s *sample) { s.Nick = nil }, nil},
+		{"notblank blank pointer", func(s *sample) { s.Nick = &blank },
+			[]FieldError{{"nick", "notblank", "cannot be blank"}}},
+		{"nested struct", func(s *sample) { s.Address = &address{} },
+			[]FieldError{{"address.street", "required", "is required"}}},
+		{"nested slice", func(s *sample) { s.Items = append(s.Items, item{VariantID: 0, Quantity: 9}) },
+			[]FieldError{
+				{"items[1].variant_id", "required", "is required"},
+				{"items[1].quantity", "max", "must be at most 5"},
+			}},
+		{"embedded struct is flattened", func(s *sample) { s.Note = "long" },
+			[]FieldError{{"note", "max", "must have at most 3 characters"}}},
+		{"first failing rule only", func(s *sample) { s.Items[0].Quantity = 0 },
+			[]FieldError{{"items[0].quantity", "min", "must be at least 1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid()
+			tt.modify(&s)
+			err := Struct(&s)
+			if got := fieldErrors(t, err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("errors = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantKind apperr.Kind
+		want     []FieldError
+	}{
+		{"valid", `{"name":"Bob","price":1,"items":[{"variant_id":1,"quantity":2}]}`, 0, nil},
+		{"syntax error", `{"name":`, apperr.KindBadRequest, nil},
+		{"type error", `{"name":"Bob","price":"cheap"}`, apperr.KindValidation,
+			[]FieldError{{"price", "type", "must be a number"}}},
+		{"rule error", `{"name":"Bob","price":1,"items":[{"variant_id":1,"quantity":0}]}`, apperr.KindValidation,
+			[]FieldError{{"items[0].quantity", "min", "must be at least 1"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+			var s sample
+			err := DecodeJSON(req, &s)
+			if tt.wantKind == 0 {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			var appErr *apperr.Error
+			if !errors.As(err, &appErr) || appErr.Kind != tt.wantKind {
+				t.Fatalf("err = %v, want kind %v", err, tt.wantKind)
+			}
+			if tt.want != nil {
+				if got := fieldErrors(t, err); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("errors = %+v, want %+v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnknownRulePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for unknown rule")
+		}
+	}()
+	Struct(struct {
+		Name string `json:"name" validate:"shiny"`
+	}{Name: "x"})
+}
+
+// fieldErrors lấy danh sách lỗi field từ ErrValidation, nil nếu err == nil
+func fieldErrors(t *testing.T, err error) []FieldError {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("err = %v, want ErrValidation", err)
+	}
+	var appErr *apperr.Error
+	errors.As(err, &appErr)
+	details, ok := appErr.Details.([]FieldError)
+	if !ok {
+		t.Fatalf("details = %T, want []FieldError", appErr.Details)
+	}
+	return details
+}
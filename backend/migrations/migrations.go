@@ -0,0 +1,281 @@
+// Package migrations chứa các file SQL đánh số phiên bản (NNNN_name.up.sql / NNNN_name.down.sql)
+// được nhúng vào binary, và bộ chạy migration lưu trạng thái trong bảng schema_migrations.
+// Mỗi dialect (mysql, postgres, sqlite) có thư mục riêng với cùng danh sách version.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed mysql/*.sql postgres/*.sql sqlite/*.sql
+var files embed.FS
+
+var fileNamePattern = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration là 1 phiên bản schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// SchemaMigration: 1 dòng trong bảng schema_migrations
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"type:varchar(255);not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// Status: trạng thái của 1 migration trong DB
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load đọc tất cả migration của dialect nhúng trong binary, sắp theo version
+func Load(dialect string) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, dialect)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q", dialect)
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		m := fileNamePattern.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("invalid migration file name: %s", e.Name())
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		content, err := files.ReadFile(path.Join(dialect, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names: %s, %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both up and down files", mig.Version, mig.Name)
+		}
+		result = append(result, *mig)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+	return result, nil
+}
+
+// applied trả về các version đã chạy (tạo bảng schema_migrations nếu chưa có)
+func applied(db *gorm.DB) (map[int64]time.Time, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var rows []SchemaMigration
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		result[row.Version] = row.AppliedAt
+	}
+	return result, nil
+}
+
+// GetStatus liệt kê mọi migration kèm thời điểm đã chạy (nil = chưa chạy)
+func GetStatus(db *gorm.DB) ([]Status, error) {
+	all, err := Load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	done, err := applied(db)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Status, 0, len(all))
+	for _, mig := range all {
+		s := Status{Migration: mig}
+		if at, ok := done[mig.Version]; ok {
+			s.AppliedAt = &at
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
+
+// Pending trả về các migration chưa chạy
+func Pending(db *gorm.DB) ([]Migration, error) {
+	statuses, err := GetStatus(db)
+	if err != nil {
+		return nil, err
+	}
+	var result []Migration
+	for _, s := range statuses {
+		if s.AppliedAt == nil {
+			result = append(result, s.Migration)
+		}
+	}
+	return result, nil
+}
+
+// Up chạy tối đa steps migration chưa chạy (steps <= 0: chạy hết)
+func Up(db *gorm.DB, steps int) ([]Migration, error) {
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+	if steps > 0 && steps < len(pending) {
+		pending = pending[:steps]
+	}
+
+	var done []Migration
+	for _, mig := range pending {
+		err := transaction(db, func(tx *gorm.DB) error {
+			if err := run(tx, mig.Up); err != nil {
+				return err
+			}
+			row := SchemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}
+			return tx.Create(&row).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s up: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// Down rollback steps migration mới nhất đã chạy (steps <= 0 được hiểu là 1)
+func Down(db *gorm.DB, steps int) ([]Migration, error) {
+	if steps <= 0 {
+		steps = 1
+	}
+	statuses, err := GetStatus(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(statuses) - 1; i >= 0 && len(done) < steps; i-- {
+		mig := statuses[i]
+		if mig.AppliedAt == nil {
+			continue
+		}
+		err := transaction(db, func(tx *gorm.DB) error {
+			if err := run(tx, mig.Down); err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, mig.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s down: %w", mig.Version, mig.Name, err)
+		}
+		done = append(done, mig.Migration)
+	}
+	return done, nil
+}
+
+// Baseline đánh dấu các migration tới version là đã chạy mà không thực thi SQL
+// (dùng cho DB cũ đã có sẵn bảng từ thời AutoMigrate)
+func Baseline(db *gorm.DB, version int64) ([]Migration, error) {
+	pending, err := Pending(db)
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, mig := range pending {
+		if mig.Version > version {
+			break
+		}
+		row := SchemaMigration{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now()}
+		if err := db.Create(&row).Error; err != nil {
+			return done, err
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// transaction chạy 1 migration trong transaction. Với SQLite, ALTER TABLE không sửa được
+// foreign key nên migration phải dựng lại bảng (tạo bảng mới, copy, drop, rename); theo
+// hướng dẫn của SQLite việc này cần tắt foreign_keys trên đúng connection chạy migration
+// (PRAGMA này không có tác dụng bên trong transaction) và foreign_key_check trước khi commit.
+func transaction(db *gorm.DB, fn func(tx *gorm.DB) error) error {
+	if db.Dialector.Name() != "sqlite" {
+		return db.Transaction(fn)
+	}
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("PRAGMA foreign_keys = ON")
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			if err := fn(tx); err != nil {
+				return err
+			}
+			var violations []map[string]interface{}
+			if err := tx.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+				return err
+			}
+			if len(violations) > 0 {
+				return fmt.Errorf("foreign key check failed: %v", violations[0])
+			}
+			return nil
+		})
+	})
+}
+
+// run thực thi từng câu lệnh trong file SQL. Postgres/SQLite rollback được cả DDL;
+// MySQL thì không (DDL tự commit) nên migration MySQL nên giữ nhỏ, lỗi giữa chừng phải sửa tay.
+func run(db *gorm.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements tách script theo dấu ';' cuối dòng, bỏ dòng comment "--"
+func splitStatements(script string) []string {
+	var stmts []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
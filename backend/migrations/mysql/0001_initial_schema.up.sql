@@ -0,0 +1,319 @@
+-- 0001: toàn bộ schema hiện tại (trước đây do AutoMigrate + tạo tay)
+
+CREATE TABLE users (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    username VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(50) DEFAULT 'customer',
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    address VARCHAR(255),
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY uni_users_username (username),
+    UNIQUE KEY uni_users_email (email)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE roles (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(50) NOT NULL,
+    description TEXT,
+    is_system BOOLEAN DEFAULT FALSE,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY uni_roles_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE permissions (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    code VARCHAR(50) NOT NULL,
+    description TEXT,
+    PRIMARY KEY (id),
+    UNIQUE KEY uni_permissions_code (code)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE role_permissions (
+    role_id BIGINT UNSIGNED NOT NULL,
+    permission_id BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_roles (
+    user_id BIGINT UNSIGNED NOT NULL,
+    role_id BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (user_id, role_id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE login_logs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED,
+    role VARCHAR(50),
+    ip VARCHAR(64),
+    user_agent TEXT,
+    status VARCHAR(50),
+    message TEXT,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_login_logs_user_id (user_id),
+    KEY idx_login_logs_ip_created_at (ip, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE refresh_tokens (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    expires_at DATETIME(3),
+    revoked_at DATETIME(3) NULL,
+    replaced_by_id BIGINT UNSIGNED NULL,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_refresh_tokens_user_id (user_id),
+    UNIQUE KEY idx_refresh_tokens_token_hash (token_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE revoked_tokens (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    jti VARCHAR(64) NOT NULL,
+    user_id BIGINT UNSIGNED,
+    expires_at DATETIME(3),
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_revoked_tokens_jti (jti),
+    KEY idx_revoked_tokens_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE password_reset_tokens (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME(3),
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_password_reset_tokens_user_id (user_id),
+    UNIQUE KEY idx_password_reset_tokens_token_hash (token_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE pending_registrations (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    address VARCHAR(255),
+    password_hash VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME(3),
+    confirmed_at DATETIME(3) NULL,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_pending_registrations_email (email),
+    UNIQUE KEY idx_pending_registrations_token_hash (token_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_totps (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN DEFAULT FALSE,
+    last_used_step BIGINT,
+    enabled_at DATETIME(3) NULL,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_user_totps_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE backup_codes (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_backup_codes_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE email_change_requests (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at DATETIME(3),
+    used_at DATETIME(3) NULL,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_email_change_requests_user_id (user_id),
+    UNIQUE KEY idx_email_change_requests_token_hash (token_hash)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE user_addresses (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NOT NULL,
+    recipient_name VARCHAR(255),
+    phone VARCHAR(20),
+    province VARCHAR(100),
+    district VARCHAR(100),
+    ward VARCHAR(100),
+    street VARCHAR(255),
+    is_default BOOLEAN DEFAULT FALSE,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_user_addresses_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE categories (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(191) NOT NULL,
+    group_name ENUM('Đồ nam','Đồ nữ','Đồ thể thao','Trẻ em','Phụ kiện') NOT NULL DEFAULT 'Đồ nam',
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY uni_categories_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE products (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    category_id BIGINT UNSIGNED,
+    image VARCHAR(255),
+    price DOUBLE,
+    discount DOUBLE DEFAULT 0,
+    discounted_price DOUBLE GENERATED ALWAYS AS (ROUND(price * (1 - discount / 100), 2)) STORED,
+    is_published BOOLEAN DEFAULT TRUE,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_products_category_id (category_id),
+    CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE product_variants (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    product_id BIGINT UNSIGNED,
+    size VARCHAR(50),
+    color VARCHAR(50),
+    price DOUBLE,
+    stock BIGINT DEFAULT 0,
+    sku VARCHAR(100),
+    image VARCHAR(255),
+    PRIMARY KEY (id),
+    KEY idx_product_variants_product_id (product_id),
+    CONSTRAINT fk_product_variants_product FOREIGN KEY (product_id) REFERENCES products (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE suppliers (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    email VARCHAR(255),
+    address VARCHAR(255),
+    created_at DATETIME(3),
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE purchases (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    supplier_id BIGINT UNSIGNED,
+    staff_id BIGINT UNSIGNED,
+    variant_id BIGINT UNSIGNED,
+    quantity BIGINT,
+    cost_price DOUBLE,
+    total DOUBLE GENERATED ALWAYS AS (quantity * cost_price) STORED,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_purchases_supplier FOREIGN KEY (supplier_id) REFERENCES suppliers (id),
+    CONSTRAINT fk_purchases_staff FOREIGN KEY (staff_id) REFERENCES users (id),
+    CONSTRAINT fk_purchases_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE inventory_logs (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    variant_id BIGINT UNSIGNED,
+    change_type ENUM('import','sale','return','adjust'),
+    quantity BIGINT,
+    note TEXT,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_inventory_logs_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE orders (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    customer_id BIGINT UNSIGNED,
+    staff_id BIGINT UNSIGNED NULL,
+    status ENUM('pending','confirmed','shipped','completed','cancelled') DEFAULT 'pending',
+    payment_method ENUM('cod','online') DEFAULT 'cod',
+    total DOUBLE,
+    created_at DATETIME(3),
+    completed_at DATETIME(3) NULL,
+    shipping_recipient_name VARCHAR(255),
+    shipping_phone VARCHAR(20),
+    shipping_province VARCHAR(100),
+    shipping_district VARCHAR(100),
+    shipping_ward VARCHAR(100),
+    shipping_street VARCHAR(255),
+    PRIMARY KEY (id),
+    KEY idx_orders_customer_id (customer_id),
+    KEY idx_orders_status (status),
+    CONSTRAINT fk_orders_customer FOREIGN KEY (customer_id) REFERENCES users (id),
+    CONSTRAINT fk_orders_staff FOREIGN KEY (staff_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE order_items (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    order_id BIGINT UNSIGNED,
+    variant_id BIGINT UNSIGNED,
+    quantity BIGINT,
+    price DOUBLE,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders (id),
+    CONSTRAINT fk_order_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE order_status_histories (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    order_id BIGINT UNSIGNED NOT NULL,
+    actor_id BIGINT UNSIGNED NULL,
+    actor_role VARCHAR(20),
+    old_status VARCHAR(20),
+    new_status VARCHAR(20) NOT NULL,
+    note TEXT,
+    created_at DATETIME(3),
+    PRIMARY KEY (id),
+    KEY idx_order_status_histories_order_id (order_id),
+    CONSTRAINT fk_order_status_histories_order FOREIGN KEY (order_id) REFERENCES orders (id),
+    CONSTRAINT fk_order_status_histories_actor FOREIGN KEY (actor_id) REFERENCES users (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE carts (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    user_id BIGINT UNSIGNED NULL,
+    guest_token VARCHAR(64) NULL,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_carts_user_id (user_id),
+    UNIQUE KEY idx_carts_guest_token (guest_token)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE cart_items (
+    id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+    cart_id BIGINT UNSIGNED NOT NULL,
+    variant_id BIGINT UNSIGNED NOT NULL,
+    quantity BIGINT NOT NULL,
+    created_at DATETIME(3),
+    updated_at DATETIME(3),
+    PRIMARY KEY (id),
+    UNIQUE KEY idx_cart_variant (cart_id, variant_id),
+    CONSTRAINT fk_carts_items FOREIGN KEY (cart_id) REFERENCES carts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
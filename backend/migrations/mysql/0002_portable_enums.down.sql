@@ -0,0 +1,13 @@
+ALTER TABLE orders
+    DROP CHECK chk_orders_status,
+    DROP CHECK chk_orders_payment_method,
+    MODIFY status ENUM('pending','confirmed','shipped','completed','cancelled') DEFAULT 'pending',
+    MODIFY payment_method ENUM('cod','online') DEFAULT 'cod';
+
+ALTER TABLE inventory_logs
+    DROP CHECK chk_inventory_logs_change_type,
+    MODIFY change_type ENUM('import','sale','return','adjust');
+
+ALTER TABLE categories
+    DROP CHECK chk_categories_group_name,
+    MODIFY group_name ENUM('Đồ nam','Đồ nữ','Đồ thể thao','Trẻ em','Phụ kiện') NOT NULL DEFAULT 'Đồ nam';
@@ -0,0 +1,15 @@
+-- 0002: thay ENUM (chỉ có ở MySQL) bằng VARCHAR + CHECK constraint (cần MySQL >= 8.0.16)
+
+ALTER TABLE categories
+    MODIFY group_name VARCHAR(50) NOT NULL DEFAULT 'Đồ nam',
+    ADD CONSTRAINT chk_categories_group_name CHECK (group_name IN ('Đồ nam','Đồ nữ','Đồ thể thao','Trẻ em','Phụ kiện'));
+
+ALTER TABLE inventory_logs
+    MODIFY change_type VARCHAR(20),
+    ADD CONSTRAINT chk_inventory_logs_change_type CHECK (change_type IN ('import','sale','return','adjust'));
+
+ALTER TABLE orders
+    MODIFY status VARCHAR(20) DEFAULT 'pending',
+    MODIFY payment_method VARCHAR(20) DEFAULT 'cod',
+    ADD CONSTRAINT chk_orders_status CHECK (status IN ('pending','confirmed','shipped','completed','cancelled')),
+    ADD CONSTRAINT chk_orders_payment_method CHECK (payment_method IN ('cod','online'));
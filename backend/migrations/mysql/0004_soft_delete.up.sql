@@ -0,0 +1,10 @@
+-- 0004: soft delete (gorm.DeletedAt) cho products, suppliers, users, categories
+
+ALTER TABLE products ADD COLUMN deleted_at DATETIME(3) NULL;
+ALTER TABLE suppliers ADD COLUMN deleted_at DATETIME(3) NULL;
+ALTER TABLE users ADD COLUMN deleted_at DATETIME(3) NULL;
+ALTER TABLE categories ADD COLUMN deleted_at DATETIME(3) NULL;
+CREATE INDEX idx_products_deleted_at ON products (deleted_at);
+CREATE INDEX idx_suppliers_deleted_at ON suppliers (deleted_at);
+CREATE INDEX idx_users_deleted_at ON users (deleted_at);
+CREATE INDEX idx_categories_deleted_at ON categories (deleted_at);
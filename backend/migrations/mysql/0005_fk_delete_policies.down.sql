@@ -0,0 +1,28 @@
+-- 0005 down: trả foreign key về mặc định (không khai báo ON DELETE) như 0001
+
+ALTER TABLE cart_items DROP FOREIGN KEY fk_cart_items_variant;
+ALTER TABLE cart_items ADD CONSTRAINT fk_cart_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id);
+ALTER TABLE order_status_histories DROP FOREIGN KEY fk_order_status_histories_actor;
+ALTER TABLE order_status_histories ADD CONSTRAINT fk_order_status_histories_actor FOREIGN KEY (actor_id) REFERENCES users (id);
+ALTER TABLE order_status_histories DROP FOREIGN KEY fk_order_status_histories_order;
+ALTER TABLE order_status_histories ADD CONSTRAINT fk_order_status_histories_order FOREIGN KEY (order_id) REFERENCES orders (id);
+ALTER TABLE order_items DROP FOREIGN KEY fk_order_items_variant;
+ALTER TABLE order_items ADD CONSTRAINT fk_order_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id);
+ALTER TABLE order_items DROP FOREIGN KEY fk_order_items_order;
+ALTER TABLE order_items ADD CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders (id);
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_staff;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_staff FOREIGN KEY (staff_id) REFERENCES users (id);
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_customer;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_customer FOREIGN KEY (customer_id) REFERENCES users (id);
+ALTER TABLE inventory_logs DROP FOREIGN KEY fk_inventory_logs_variant;
+ALTER TABLE inventory_logs ADD CONSTRAINT fk_inventory_logs_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id);
+ALTER TABLE purchases DROP FOREIGN KEY fk_purchases_variant;
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id);
+ALTER TABLE purchases DROP FOREIGN KEY fk_purchases_staff;
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_staff FOREIGN KEY (staff_id) REFERENCES users (id);
+ALTER TABLE purchases DROP FOREIGN KEY fk_purchases_supplier;
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_supplier FOREIGN KEY (supplier_id) REFERENCES suppliers (id);
+ALTER TABLE product_variants DROP FOREIGN KEY fk_product_variants_product;
+ALTER TABLE product_variants ADD CONSTRAINT fk_product_variants_product FOREIGN KEY (product_id) REFERENCES products (id);
+ALTER TABLE products DROP FOREIGN KEY fk_products_category;
+ALTER TABLE products ADD CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories (id);
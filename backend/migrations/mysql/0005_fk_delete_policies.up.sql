@@ -0,0 +1,31 @@
+-- 0005: ON DELETE rõ ràng cho các foreign key
+-- RESTRICT: dữ liệu lịch sử (đơn hàng, phiếu nhập, inventory log) chặn xóa vĩnh viễn bản ghi cha
+-- CASCADE: dữ liệu thuộc hẳn về bản ghi cha (variant của product, dòng hàng của order, giỏ hàng)
+-- SET NULL: chỉ ghi nhận người thao tác (staff xử lý đơn, actor trong timeline)
+
+ALTER TABLE products DROP FOREIGN KEY fk_products_category;
+ALTER TABLE products ADD CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories (id) ON DELETE RESTRICT;
+ALTER TABLE product_variants DROP FOREIGN KEY fk_product_variants_product;
+ALTER TABLE product_variants ADD CONSTRAINT fk_product_variants_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE;
+ALTER TABLE purchases DROP FOREIGN KEY fk_purchases_supplier;
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_supplier FOREIGN KEY (supplier_id) REFERENCES suppliers (id) ON DELETE RESTRICT;
+ALTER TABLE purchases DROP FOREIGN KEY fk_purchases_staff;
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_staff FOREIGN KEY (staff_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE purchases DROP FOREIGN KEY fk_purchases_variant;
+ALTER TABLE purchases ADD CONSTRAINT fk_purchases_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE RESTRICT;
+ALTER TABLE inventory_logs DROP FOREIGN KEY fk_inventory_logs_variant;
+ALTER TABLE inventory_logs ADD CONSTRAINT fk_inventory_logs_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE RESTRICT;
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_customer;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_customer FOREIGN KEY (customer_id) REFERENCES users (id) ON DELETE RESTRICT;
+ALTER TABLE orders DROP FOREIGN KEY fk_orders_staff;
+ALTER TABLE orders ADD CONSTRAINT fk_orders_staff FOREIGN KEY (staff_id) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE order_items DROP FOREIGN KEY fk_order_items_order;
+ALTER TABLE order_items ADD CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE;
+ALTER TABLE order_items DROP FOREIGN KEY fk_order_items_variant;
+ALTER TABLE order_items ADD CONSTRAINT fk_order_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE RESTRICT;
+ALTER TABLE order_status_histories DROP FOREIGN KEY fk_order_status_histories_order;
+ALTER TABLE order_status_histories ADD CONSTRAINT fk_order_status_histories_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE;
+ALTER TABLE order_status_histories DROP FOREIGN KEY fk_order_status_histories_actor;
+ALTER TABLE order_status_histories ADD CONSTRAINT fk_order_status_histories_actor FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL;
+ALTER TABLE cart_items DROP FOREIGN KEY fk_cart_items_variant;
+ALTER TABLE cart_items ADD CONSTRAINT fk_cart_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE CASCADE;
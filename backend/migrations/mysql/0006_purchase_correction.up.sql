@@ -0,0 +1,6 @@
+-- 0006: change_type purchase_correction cho log sửa / xóa phiếu nhập (quantity là chênh lệch có dấu),
+-- tách khỏi adjust (quantity là tồn kho mới)
+
+ALTER TABLE inventory_logs
+    DROP CHECK chk_inventory_logs_change_type,
+    ADD CONSTRAINT chk_inventory_logs_change_type CHECK (change_type IN ('import','sale','return','adjust','purchase_correction'));
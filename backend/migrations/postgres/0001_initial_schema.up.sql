@@ -0,0 +1,323 @@
+-- 0001: toàn bộ schema hiện tại (trước đây do AutoMigrate + tạo tay)
+
+CREATE TABLE users (
+    id BIGSERIAL NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    password_hash VARCHAR(255) NOT NULL,
+    role VARCHAR(50) DEFAULT 'customer',
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    address VARCHAR(255),
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_users_username UNIQUE (username),
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+
+CREATE TABLE roles (
+    id BIGSERIAL NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    description TEXT,
+    is_system BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_roles_name UNIQUE (name)
+);
+
+CREATE TABLE permissions (
+    id BIGSERIAL NOT NULL,
+    code VARCHAR(50) NOT NULL,
+    description TEXT,
+    PRIMARY KEY (id),
+    CONSTRAINT uni_permissions_code UNIQUE (code)
+);
+
+CREATE TABLE role_permissions (
+    role_id BIGINT NOT NULL,
+    permission_id BIGINT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions (id) ON DELETE CASCADE
+);
+
+CREATE TABLE user_roles (
+    user_id BIGINT NOT NULL,
+    role_id BIGINT NOT NULL,
+    PRIMARY KEY (user_id, role_id),
+    CONSTRAINT fk_user_roles_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_user_roles_role FOREIGN KEY (role_id) REFERENCES roles (id) ON DELETE CASCADE
+);
+
+CREATE TABLE login_logs (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT,
+    role VARCHAR(50),
+    ip VARCHAR(64),
+    user_agent TEXT,
+    status VARCHAR(50),
+    message TEXT,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_login_logs_user_id ON login_logs (user_id);
+CREATE INDEX idx_login_logs_ip_created_at ON login_logs (ip, created_at);
+
+CREATE TABLE refresh_tokens (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    ip VARCHAR(64),
+    user_agent TEXT,
+    expires_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ NULL,
+    replaced_by_id BIGINT NULL,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_refresh_tokens_token_hash UNIQUE (token_hash)
+);
+CREATE INDEX idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+
+CREATE TABLE revoked_tokens (
+    id BIGSERIAL NOT NULL,
+    jti VARCHAR(64) NOT NULL,
+    user_id BIGINT,
+    expires_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_revoked_tokens_jti UNIQUE (jti)
+);
+CREATE INDEX idx_revoked_tokens_user_id ON revoked_tokens (user_id);
+
+CREATE TABLE password_reset_tokens (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_password_reset_tokens_token_hash UNIQUE (token_hash)
+);
+CREATE INDEX idx_password_reset_tokens_user_id ON password_reset_tokens (user_id);
+
+CREATE TABLE pending_registrations (
+    id BIGSERIAL NOT NULL,
+    username VARCHAR(255) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    address VARCHAR(255),
+    password_hash VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ,
+    confirmed_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_pending_registrations_email UNIQUE (email),
+    CONSTRAINT idx_pending_registrations_token_hash UNIQUE (token_hash)
+);
+
+CREATE TABLE user_totps (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN DEFAULT FALSE,
+    last_used_step BIGINT,
+    enabled_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_user_totps_user_id UNIQUE (user_id)
+);
+
+CREATE TABLE backup_codes (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_backup_codes_user_id ON backup_codes (user_id);
+
+CREATE TABLE email_change_requests (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash CHAR(64) NOT NULL,
+    expires_at TIMESTAMPTZ,
+    used_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_email_change_requests_token_hash UNIQUE (token_hash)
+);
+CREATE INDEX idx_email_change_requests_user_id ON email_change_requests (user_id);
+
+CREATE TABLE user_addresses (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT NOT NULL,
+    recipient_name VARCHAR(255),
+    phone VARCHAR(20),
+    province VARCHAR(100),
+    district VARCHAR(100),
+    ward VARCHAR(100),
+    street VARCHAR(255),
+    is_default BOOLEAN DEFAULT FALSE,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (id)
+);
+CREATE INDEX idx_user_addresses_user_id ON user_addresses (user_id);
+
+CREATE TABLE categories (
+    id BIGSERIAL NOT NULL,
+    name VARCHAR(191) NOT NULL,
+    group_name VARCHAR(50) NOT NULL DEFAULT 'Đồ nam',
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT chk_categories_group_name CHECK (group_name IN ('Đồ nam','Đồ nữ','Đồ thể thao','Trẻ em','Phụ kiện')),
+    CONSTRAINT uni_categories_name UNIQUE (name)
+);
+
+CREATE TABLE products (
+    id BIGSERIAL NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    category_id BIGINT,
+    image VARCHAR(255),
+    price DOUBLE PRECISION,
+    discount DOUBLE PRECISION DEFAULT 0,
+    discounted_price DOUBLE PRECISION GENERATED ALWAYS AS (ROUND((price * (1 - discount / 100))::numeric, 2)::double precision) STORED,
+    is_published BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_products_category FOREIGN KEY (category_id) REFERENCES categories (id)
+);
+CREATE INDEX idx_products_category_id ON products (category_id);
+
+CREATE TABLE product_variants (
+    id BIGSERIAL NOT NULL,
+    product_id BIGINT,
+    size VARCHAR(50),
+    color VARCHAR(50),
+    price DOUBLE PRECISION,
+    stock BIGINT DEFAULT 0,
+    sku VARCHAR(100),
+    image VARCHAR(255),
+    PRIMARY KEY (id),
+    CONSTRAINT fk_product_variants_product FOREIGN KEY (product_id) REFERENCES products (id)
+);
+CREATE INDEX idx_product_variants_product_id ON product_variants (product_id);
+
+CREATE TABLE suppliers (
+    id BIGSERIAL NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    email VARCHAR(255),
+    address VARCHAR(255),
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id)
+);
+
+CREATE TABLE purchases (
+    id BIGSERIAL NOT NULL,
+    supplier_id BIGINT,
+    staff_id BIGINT,
+    variant_id BIGINT,
+    quantity BIGINT,
+    cost_price DOUBLE PRECISION,
+    total DOUBLE PRECISION GENERATED ALWAYS AS (quantity * cost_price) STORED,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_purchases_supplier FOREIGN KEY (supplier_id) REFERENCES suppliers (id),
+    CONSTRAINT fk_purchases_staff FOREIGN KEY (staff_id) REFERENCES users (id),
+    CONSTRAINT fk_purchases_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+);
+
+CREATE TABLE inventory_logs (
+    id BIGSERIAL NOT NULL,
+    variant_id BIGINT,
+    change_type VARCHAR(20),
+    quantity BIGINT,
+    note TEXT,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT chk_inventory_logs_change_type CHECK (change_type IN ('import','sale','return','adjust')),
+    CONSTRAINT fk_inventory_logs_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+);
+
+CREATE TABLE orders (
+    id BIGSERIAL NOT NULL,
+    customer_id BIGINT,
+    staff_id BIGINT NULL,
+    status VARCHAR(20) DEFAULT 'pending',
+    payment_method VARCHAR(20) DEFAULT 'cod',
+    total DOUBLE PRECISION,
+    created_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ NULL,
+    shipping_recipient_name VARCHAR(255),
+    shipping_phone VARCHAR(20),
+    shipping_province VARCHAR(100),
+    shipping_district VARCHAR(100),
+    shipping_ward VARCHAR(100),
+    shipping_street VARCHAR(255),
+    PRIMARY KEY (id),
+    CONSTRAINT chk_orders_status CHECK (status IN ('pending','confirmed','shipped','completed','cancelled')),
+    CONSTRAINT chk_orders_payment_method CHECK (payment_method IN ('cod','online')),
+    CONSTRAINT fk_orders_customer FOREIGN KEY (customer_id) REFERENCES users (id),
+    CONSTRAINT fk_orders_staff FOREIGN KEY (staff_id) REFERENCES users (id)
+);
+CREATE INDEX idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX idx_orders_status ON orders (status);
+
+CREATE TABLE order_items (
+    id BIGSERIAL NOT NULL,
+    order_id BIGINT,
+    variant_id BIGINT,
+    quantity BIGINT,
+    price DOUBLE PRECISION,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders (id),
+    CONSTRAINT fk_order_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+);
+
+CREATE TABLE order_status_histories (
+    id BIGSERIAL NOT NULL,
+    order_id BIGINT NOT NULL,
+    actor_id BIGINT NULL,
+    actor_role VARCHAR(20),
+    old_status VARCHAR(20),
+    new_status VARCHAR(20) NOT NULL,
+    note TEXT,
+    created_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT fk_order_status_histories_order FOREIGN KEY (order_id) REFERENCES orders (id),
+    CONSTRAINT fk_order_status_histories_actor FOREIGN KEY (actor_id) REFERENCES users (id)
+);
+CREATE INDEX idx_order_status_histories_order_id ON order_status_histories (order_id);
+
+CREATE TABLE carts (
+    id BIGSERIAL NOT NULL,
+    user_id BIGINT NULL,
+    guest_token VARCHAR(64) NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_carts_user_id UNIQUE (user_id),
+    CONSTRAINT idx_carts_guest_token UNIQUE (guest_token)
+);
+
+CREATE TABLE cart_items (
+    id BIGSERIAL NOT NULL,
+    cart_id BIGINT NOT NULL,
+    variant_id BIGINT NOT NULL,
+    quantity BIGINT NOT NULL,
+    created_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    PRIMARY KEY (id),
+    CONSTRAINT idx_cart_variant UNIQUE (cart_id, variant_id),
+    CONSTRAINT fk_carts_items FOREIGN KEY (cart_id) REFERENCES carts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+);
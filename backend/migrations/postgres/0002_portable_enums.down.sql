@@ -0,0 +1 @@
+-- 0002: chỉ cần cho MySQL (ENUM -> CHECK); schema 0001 của dialect này đã dùng CHECK constraint
@@ -0,0 +1,8 @@
+DROP INDEX idx_categories_deleted_at;
+DROP INDEX idx_users_deleted_at;
+DROP INDEX idx_suppliers_deleted_at;
+DROP INDEX idx_products_deleted_at;
+ALTER TABLE categories DROP COLUMN deleted_at;
+ALTER TABLE users DROP COLUMN deleted_at;
+ALTER TABLE suppliers DROP COLUMN deleted_at;
+ALTER TABLE products DROP COLUMN deleted_at;
@@ -0,0 +1,6 @@
+-- 0006 down: log purchase_correction được ghi lại thành adjust như trước
+
+UPDATE inventory_logs SET change_type = 'adjust' WHERE change_type = 'purchase_correction';
+
+ALTER TABLE inventory_logs DROP CONSTRAINT chk_inventory_logs_change_type;
+ALTER TABLE inventory_logs ADD CONSTRAINT chk_inventory_logs_change_type CHECK (change_type IN ('import','sale','return','adjust'));
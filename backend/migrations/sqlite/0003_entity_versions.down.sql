@@ -0,0 +1,4 @@
+ALTER TABLE categories DROP COLUMN version;
+ALTER TABLE suppliers DROP COLUMN version;
+ALTER TABLE product_variants DROP COLUMN version;
+ALTER TABLE products DROP COLUMN version;
@@ -0,0 +1,6 @@
+-- 0003: cột version cho optimistic concurrency (ETag / If-Match)
+
+ALTER TABLE products ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE product_variants ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE suppliers ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+ALTER TABLE categories ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,104 @@
+-- 0005: ON DELETE rõ ràng cho các foreign key
+-- SQLite không ALTER được constraint nên dựng lại bảng (runner đã tắt foreign_keys khi chạy).
+-- Các FK giữ RESTRICT không cần dựng lại: NO ACTION (kiểm tra ngay) ở SQLite chặn xóa y như RESTRICT.
+
+-- product_variants.product_id: CASCADE (xóa vĩnh viễn product kéo theo variant)
+CREATE TABLE product_variants_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    product_id BIGINT,
+    size VARCHAR(50),
+    color VARCHAR(50),
+    price DOUBLE,
+    stock BIGINT DEFAULT 0,
+    sku VARCHAR(100),
+    image VARCHAR(255),
+    version BIGINT NOT NULL DEFAULT 1,
+    CONSTRAINT fk_product_variants_product FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE
+);
+INSERT INTO product_variants_new (id, product_id, size, color, price, stock, sku, image, version)
+SELECT id, product_id, size, color, price, stock, sku, image, version FROM product_variants;
+DROP TABLE product_variants;
+ALTER TABLE product_variants_new RENAME TO product_variants;
+CREATE INDEX idx_product_variants_product_id ON product_variants (product_id);
+
+-- orders.staff_id: SET NULL
+CREATE TABLE orders_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_id BIGINT,
+    staff_id BIGINT NULL,
+    status VARCHAR(20) DEFAULT 'pending',
+    payment_method VARCHAR(20) DEFAULT 'cod',
+    total DOUBLE,
+    created_at DATETIME,
+    completed_at DATETIME NULL,
+    shipping_recipient_name VARCHAR(255),
+    shipping_phone VARCHAR(20),
+    shipping_province VARCHAR(100),
+    shipping_district VARCHAR(100),
+    shipping_ward VARCHAR(100),
+    shipping_street VARCHAR(255),
+    CONSTRAINT chk_orders_status CHECK (status IN ('pending','confirmed','shipped','completed','cancelled')),
+    CONSTRAINT chk_orders_payment_method CHECK (payment_method IN ('cod','online')),
+    CONSTRAINT fk_orders_customer FOREIGN KEY (customer_id) REFERENCES users (id) ON DELETE RESTRICT,
+    CONSTRAINT fk_orders_staff FOREIGN KEY (staff_id) REFERENCES users (id) ON DELETE SET NULL
+);
+INSERT INTO orders_new (id, customer_id, staff_id, status, payment_method, total, created_at, completed_at,
+    shipping_recipient_name, shipping_phone, shipping_province, shipping_district, shipping_ward, shipping_street)
+SELECT id, customer_id, staff_id, status, payment_method, total, created_at, completed_at,
+    shipping_recipient_name, shipping_phone, shipping_province, shipping_district, shipping_ward, shipping_street
+FROM orders;
+DROP TABLE orders;
+ALTER TABLE orders_new RENAME TO orders;
+CREATE INDEX idx_orders_customer_id ON orders (customer_id);
+CREATE INDEX idx_orders_status ON orders (status);
+
+-- order_items.order_id: CASCADE, order_items.variant_id: RESTRICT
+CREATE TABLE order_items_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id BIGINT,
+    variant_id BIGINT,
+    quantity BIGINT,
+    price DOUBLE,
+    CONSTRAINT fk_order_items_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE RESTRICT
+);
+INSERT INTO order_items_new (id, order_id, variant_id, quantity, price)
+SELECT id, order_id, variant_id, quantity, price FROM order_items;
+DROP TABLE order_items;
+ALTER TABLE order_items_new RENAME TO order_items;
+
+-- order_status_histories.order_id: CASCADE, actor_id: SET NULL
+CREATE TABLE order_status_histories_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    order_id BIGINT NOT NULL,
+    actor_id BIGINT NULL,
+    actor_role VARCHAR(20),
+    old_status VARCHAR(20),
+    new_status VARCHAR(20) NOT NULL,
+    note TEXT,
+    created_at DATETIME,
+    CONSTRAINT fk_order_status_histories_order FOREIGN KEY (order_id) REFERENCES orders (id) ON DELETE CASCADE,
+    CONSTRAINT fk_order_status_histories_actor FOREIGN KEY (actor_id) REFERENCES users (id) ON DELETE SET NULL
+);
+INSERT INTO order_status_histories_new (id, order_id, actor_id, actor_role, old_status, new_status, note, created_at)
+SELECT id, order_id, actor_id, actor_role, old_status, new_status, note, created_at FROM order_status_histories;
+DROP TABLE order_status_histories;
+ALTER TABLE order_status_histories_new RENAME TO order_status_histories;
+CREATE INDEX idx_order_status_histories_order_id ON order_status_histories (order_id);
+
+-- cart_items.variant_id: CASCADE (giỏ hàng không phải dữ liệu lịch sử)
+CREATE TABLE cart_items_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cart_id BIGINT NOT NULL,
+    variant_id BIGINT NOT NULL,
+    quantity BIGINT NOT NULL,
+    created_at DATETIME,
+    updated_at DATETIME,
+    CONSTRAINT idx_cart_variant UNIQUE (cart_id, variant_id),
+    CONSTRAINT fk_carts_items FOREIGN KEY (cart_id) REFERENCES carts (id) ON DELETE CASCADE,
+    CONSTRAINT fk_cart_items_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id) ON DELETE CASCADE
+);
+INSERT INTO cart_items_new (id, cart_id, variant_id, quantity, created_at, updated_at)
+SELECT id, cart_id, variant_id, quantity, created_at, updated_at FROM cart_items;
+DROP TABLE cart_items;
+ALTER TABLE cart_items_new RENAME TO cart_items;
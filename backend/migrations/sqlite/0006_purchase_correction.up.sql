@@ -0,0 +1,18 @@
+-- 0006: change_type purchase_correction cho log sửa / xóa phiếu nhập (quantity là chênh lệch có dấu),
+-- tách khỏi adjust (quantity là tồn kho mới)
+-- SQLite không ALTER được CHECK constraint nên dựng lại bảng.
+
+CREATE TABLE inventory_logs_new (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    variant_id BIGINT,
+    change_type VARCHAR(20),
+    quantity BIGINT,
+    note TEXT,
+    created_at DATETIME,
+    CONSTRAINT chk_inventory_logs_change_type CHECK (change_type IN ('import','sale','return','adjust','purchase_correction')),
+    CONSTRAINT fk_inventory_logs_variant FOREIGN KEY (variant_id) REFERENCES product_variants (id)
+);
+INSERT INTO inventory_logs_new (id, variant_id, change_type, quantity, note, created_at)
+SELECT id, variant_id, change_type, quantity, note, created_at FROM inventory_logs;
+DROP TABLE inventory_logs;
+ALTER TABLE inventory_logs_new RENAME TO inventory_logs;